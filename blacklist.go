@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// publicIPURL answers with the caller's public IP address as plain text
+const publicIPURL = "https://api.ipify.org"
+
+// checkBlacklist warns when our public IP is on a DNS blocklist, which
+// explains otherwise mysterious mass rejections
+func checkBlacklist() {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	ip, err := publicIP(ctx)
+	if err != nil {
+		color.Yellow("⚠️ Could not determine public IP for the blocklist check: %v", err)
+		return
+	}
+	listed, err := checker.Blacklisted(ctx, ip)
+	switch {
+	case err != nil:
+		color.Yellow("⚠️ Could not query DNS blocklists for %s: %v", ip, err)
+	case len(listed) > 0:
+		color.Red("🛑 Public IP %s is blocklisted on %s; expect mail servers to reject probes", ip, strings.Join(listed, ", "))
+	default:
+		color.Green("✅ Public IP %s is not on any checked DNS blocklist", ip)
+	}
+}
+
+// publicIP asks an external service which address our traffic comes from
+func publicIP(ctx context.Context) (net.IP, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, publicIPURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", publicIPURL, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64))
+	if err != nil {
+		return nil, err
+	}
+	ip := net.ParseIP(strings.TrimSpace(string(body)))
+	if ip == nil {
+		return nil, fmt.Errorf("%s returned %q, not an IP address", publicIPURL, body)
+	}
+	return ip, nil
+}
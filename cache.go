@@ -0,0 +1,156 @@
+package main
+
+import (
+	"container/list"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"email-verifier/verifier"
+)
+
+// cache, when set, reuses results from earlier runs
+var cache *resultCache
+
+// cacheEntry is a stored result and when it was produced
+type cacheEntry struct {
+	Result  verifier.Result `json:"result"`
+	Checked time.Time       `json:"checked"`
+}
+
+// resultCache persists verification results in a JSON file so repeat runs
+// within the TTL skip the SMTP probes entirely. It holds at most size
+// entries, evicting the least recently used.
+type resultCache struct {
+	mu      sync.Mutex
+	path    string
+	ttl     time.Duration
+	size    int
+	entries map[string]cacheEntry
+	order   *list.List // of keys, most recently used first
+	elems   map[string]*list.Element
+}
+
+// loadResultCache reads the cache file at path, dropping expired entries,
+// entries without a definite verdict and, beyond size, the oldest. A
+// missing file yields an empty cache.
+func loadResultCache(path string, ttl time.Duration, size int) (*resultCache, error) {
+	c := &resultCache{
+		path:    path,
+		ttl:     ttl,
+		size:    size,
+		entries: make(map[string]cacheEntry),
+		order:   list.New(),
+		elems:   make(map[string]*list.Element),
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(c.entries))
+	for key, entry := range c.entries {
+		if c.expired(entry) || !cacheable(entry.Result, nil) {
+			delete(c.entries, key)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	// Newest results count as most recently used
+	sort.Slice(keys, func(i, j int) bool {
+		return c.entries[keys[i]].Checked.Before(c.entries[keys[j]].Checked)
+	})
+	for _, key := range keys {
+		c.elems[key] = c.order.PushFront(key)
+	}
+	c.evict()
+	return c, nil
+}
+
+// cacheable reports whether a result is a definite verdict worth reusing.
+// Skipped checks are not: a later full run must still probe the mailbox.
+func cacheable(res verifier.Result, err error) bool {
+	switch outcome(res, err) {
+	case "deliverable", "undeliverable", "catch_all":
+		return true
+	}
+	return false
+}
+
+// evict drops least recently used entries until the cache fits its size
+func (c *resultCache) evict() {
+	for c.size > 0 && c.order.Len() > c.size {
+		oldest := c.order.Back()
+		key := oldest.Value.(string)
+		c.order.Remove(oldest)
+		delete(c.elems, key)
+		delete(c.entries, key)
+	}
+}
+
+// expired reports whether an entry is older than the TTL
+func (c *resultCache) expired(entry cacheEntry) bool {
+	return c.ttl > 0 && time.Since(entry.Checked) > c.ttl
+}
+
+// get returns the cached result for an email if it is still fresh
+func (c *resultCache) get(email string) (verifier.Result, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := strings.ToLower(email)
+	entry, ok := c.entries[key]
+	if !ok || c.expired(entry) {
+		return verifier.Result{}, false
+	}
+	c.order.MoveToFront(c.elems[key])
+	return entry.Result, true
+}
+
+// put stores a result for an email
+func (c *resultCache) put(email string, res verifier.Result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := strings.ToLower(email)
+	c.entries[key] = cacheEntry{Result: res, Checked: time.Now()}
+	if elem, ok := c.elems[key]; ok {
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.elems[key] = c.order.PushFront(key)
+	c.evict()
+}
+
+// save writes the cache back to disk, replacing the file atomically
+func (c *resultCache) save() error {
+	c.mu.Lock()
+	data, err := json.Marshal(c.entries)
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".cache-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path)
+}
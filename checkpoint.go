@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+
+	"email-verifier/verifier"
+)
+
+// checkpoint, when set, records finished emails so a restarted run can
+// skip them
+var checkpoint *checkpointFile
+
+// checkpointFile is an append-only list of finished emails, one per line.
+// Each email is written as soon as its result is out, so even a killed run
+// leaves a usable record.
+type checkpointFile struct {
+	mu   sync.Mutex // serializes writes
+	file *os.File
+	done map[string]bool // emails recorded by earlier runs
+}
+
+// openCheckpoint loads the emails already recorded at path and opens it for
+// appending. A missing file starts an empty checkpoint.
+func openCheckpoint(path string) (*checkpointFile, error) {
+	c := &checkpointFile{done: make(map[string]bool)}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	// A kill mid-write can leave a partial last line; it is not trusted
+	if i := bytes.LastIndexByte(data, '\n'); i < len(data)-1 {
+		data = data[:i+1]
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			c.done[strings.ToLower(line)] = true
+		}
+	}
+	if c.file, err = os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644); err != nil {
+		return nil, err
+	}
+	// Drop any partial line so the next record starts on its own line
+	if err := c.file.Truncate(int64(len(data))); err != nil {
+		c.file.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// completed reports whether an earlier run already finished the email
+func (c *checkpointFile) completed(email string) bool {
+	return c.done[strings.ToLower(email)]
+}
+
+// record marks an email as finished. Transient errors are not recorded so
+// the email is tried again on restart.
+func (c *checkpointFile) record(email string, err error) {
+	if err != nil || email == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.file.WriteString(email + "\n")
+}
+
+// close closes the checkpoint file
+func (c *checkpointFile) close() error {
+	return c.file.Close()
+}
+
+// resumed reports whether the checkpoint says an earlier run finished the
+// email, counting it as skipped
+func resumed(email string) bool {
+	if checkpoint == nil || !checkpoint.completed(email) {
+		return false
+	}
+	stats.resumed++
+	return true
+}
+
+// markDone records a result in the checkpoint, if there is one, once it has
+// been written out
+func markDone(res verifier.Result, err error) {
+	if checkpoint != nil {
+		checkpoint.record(res.Email, err)
+	}
+}
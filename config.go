@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// loadConfig applies the settings in a JSON file to every flag not given on
+// the command line. The file is an object keyed by flag name, for example
+// {"timeout": "10s", "concurrency": 20, "no-smtp": true}.
+func loadConfig(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var settings map[string]json.RawMessage
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return fmt.Errorf("malformed config %s: %w", path, err)
+	}
+
+	explicit := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if flag.Lookup(name) == nil || name == "config" {
+			return fmt.Errorf("unknown setting %q in %s", name, path)
+		}
+		if explicit[name] {
+			continue
+		}
+		value, err := configValue(settings[name])
+		if err != nil {
+			return fmt.Errorf("setting %q in %s: %w", name, path, err)
+		}
+		if err := flag.Set(name, value); err != nil {
+			return fmt.Errorf("setting %q in %s: %w", name, path, err)
+		}
+	}
+	return nil
+}
+
+// configValue converts a JSON string, number or boolean to the text form a
+// flag accepts
+func configValue(raw json.RawMessage) (string, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return "", err
+	}
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		return "", fmt.Errorf("must be a string, number or boolean")
+	}
+}
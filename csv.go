@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"email-verifier/verifier"
+
+	"github.com/fatih/color"
+)
+
+// csvMode reads input as CSV with a header row and writes CSV results
+var csvMode bool
+
+// emailColumn names the CSV column holding the email, by header name or
+// zero-based index
+var emailColumn = "email"
+
+// csvResultColumns are appended to every row of the output CSV
+var csvResultColumns = []string{"valid", "mx_found", "smtp_ok", "status", "reason"}
+
+// processCSV verifies the email column of each CSV row read from r and
+// writes the rows to stdout with the verification columns appended
+func processCSV(r io.Reader, total int) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		color.Red("❌ Failed to read CSV header: %v", err)
+		return
+	}
+	col, err := findColumn(header, emailColumn)
+	if err != nil {
+		color.Red("❌ %v", err)
+		return
+	}
+
+	writer := csv.NewWriter(output)
+	defer writer.Flush()
+	writer.Write(append(append([]string{}, header...), csvResultColumns...))
+
+	// Rows wait here until their result comes back; results arrive in input
+	// order so the oldest row always belongs to the next result
+	var mu sync.Mutex
+	var queue [][]string
+
+	emails := make(chan string)
+	var readErr error
+	go func() {
+		defer close(emails)
+		for {
+			row, err := reader.Read()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				readErr = err
+				return
+			}
+			email := ""
+			if col < len(row) {
+				email = strings.TrimSpace(row[col])
+			}
+			if email != "" && resumed(email) {
+				continue
+			}
+			mu.Lock()
+			queue = append(queue, row)
+			mu.Unlock()
+			emails <- email
+		}
+	}()
+
+	bar := newProgress(total)
+	verifyAll(runCtx, emails, options.Concurrency, func(res verifier.Result, err error) {
+		defer bar.advance()
+		mu.Lock()
+		row := queue[0]
+		queue = queue[1:]
+		mu.Unlock()
+
+		if filtered(res) {
+			markDone(res, err)
+			return
+		}
+		stats.record(res, err)
+
+		// Pad short rows so the result columns line up with the header
+		for len(row) < len(header) {
+			row = append(row, "")
+		}
+		writer.Write(append(row,
+			strconv.FormatBool(res.SyntaxValid),
+			strconv.FormatBool(len(res.MXRecords) > 0),
+			csvSMTPOK(res),
+			string(res.Status),
+			res.Reason,
+		))
+		// The row must be on disk before the checkpoint claims it
+		if checkpoint != nil {
+			writer.Flush()
+			markDone(res, err)
+		}
+	})
+
+	bar.clear()
+	if readErr != nil {
+		color.Red("❌ Error reading CSV: %v", readErr)
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		color.Red("❌ Error writing CSV: %v", err)
+	}
+	stats.printSummary()
+}
+
+// csvSMTPOK renders the smtp_ok column, which is "skipped" when the SMTP
+// check did not run
+func csvSMTPOK(res verifier.Result) string {
+	if ok := smtpOK(res); ok != nil {
+		return strconv.FormatBool(*ok)
+	}
+	return "skipped"
+}
+
+// findColumn resolves a column given by header name or zero-based index
+func findColumn(header []string, spec string) (int, error) {
+	if i, err := strconv.Atoi(spec); err == nil {
+		if i < 0 || i >= len(header) {
+			return 0, fmt.Errorf("email column %d is out of range", i)
+		}
+		return i, nil
+	}
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), spec) {
+			return i, nil
+		}
+	}
+	return 0, errors.New("no column named " + strconv.Quote(spec) + " in CSV header")
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// domainList reads a list flag such as -allow-domains or -rotate-from: the
+// path of a file with one entry per line (# starts a comment), or a
+// comma-separated list
+func domainList(value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	text := strings.ReplaceAll(value, ",", "\n")
+	if info, err := os.Stat(value); err == nil && !info.IsDir() {
+		data, err := os.ReadFile(value)
+		if err != nil {
+			return nil, err
+		}
+		text = string(data)
+	}
+
+	var domains []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			domains = append(domains, line)
+		}
+	}
+	return domains, nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+	"text/template"
+
+	"email-verifier/verifier"
+
+	"github.com/fatih/color"
+)
+
+// outputFormat, when set, renders each result with a user-supplied
+// text/template instead of the colored text
+var outputFormat *template.Template
+
+// templateResult is what -format templates see: every Result field plus a
+// few derived ones the JSON output also has
+type templateResult struct {
+	verifier.Result
+	MXFound bool
+	Error   string
+}
+
+// parseFormat compiles a -format template; a template without a trailing
+// newline gets one so each result stays on its own line
+func parseFormat(text string) (*template.Template, error) {
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	return template.New("format").Parse(text)
+}
+
+// printFormatted writes a result rendered with the -format template
+func printFormatted(res verifier.Result) {
+	data := templateResult{
+		Result:  res,
+		MXFound: len(res.MXRecords) > 0,
+		Error:   errorText(res),
+	}
+	if err := outputFormat.Execute(output, data); err != nil {
+		color.Red("❌ Failed to format result for %s: %v", res.Email, err)
+	}
+}
+
+// plainText reports whether results are printed as colored text for a
+// person, rather than as JSON or a -format template
+func plainText() bool {
+	return !jsonOutput && outputFormat == nil
+}
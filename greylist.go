@@ -0,0 +1,53 @@
+package main
+
+import (
+	"time"
+
+	"email-verifier/verifier"
+
+	"github.com/fatih/color"
+)
+
+// greylistPass re-verifies greylisted addresses once the first pass over a
+// file is done
+var greylistPass bool
+
+// greylistDelay is how long the second pass waits, giving greylisting
+// servers time to start accepting our retry
+var greylistDelay = 5 * time.Minute
+
+// greylisted reports whether the server answered the mailbox with a
+// temporary 4xx reply, which greylisting servers send to unknown senders
+func greylisted(res verifier.Result) bool {
+	return res.SMTPCode >= 400 && res.SMTPCode < 500
+}
+
+// retryGreylisted waits greylistDelay and verifies the deferred emails
+// again, passing the second results to emit. An interrupted run reports
+// the first results instead.
+func retryGreylisted(deferred []indexedResult, emit func(verifier.Result, error)) {
+	color.Yellow("⏳ %d greylisted addresses, retrying in %s", len(deferred), greylistDelay)
+	select {
+	case <-time.After(greylistDelay):
+	case <-stopping:
+		for _, r := range deferred {
+			emit(r.res, r.err)
+		}
+		return
+	}
+
+	emails := make(chan string)
+	go func() {
+		defer close(emails)
+		for _, r := range deferred {
+			emails <- r.res.Email
+		}
+	}()
+	bar := newProgress(len(deferred))
+	verifyAll(runCtx, emails, options.Concurrency, func(res verifier.Result, err error) {
+		bar.clear()
+		defer bar.advance()
+		emit(res, err)
+	})
+	bar.clear()
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"email-verifier/verifier"
+
+	"github.com/fatih/color"
+)
+
+// groupByDomain buffers file results and prints them grouped by domain
+// instead of in input order
+var groupByDomain bool
+
+// printGrouped reports buffered results sorted by domain, keeping input
+// order within a domain. In text output each domain gets a header with the
+// mail server details its addresses share.
+func printGrouped(results []indexedResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		return strings.ToLower(results[i].res.Domain) < strings.ToLower(results[j].res.Domain)
+	})
+	// header is the domain of the last header printed, if any
+	var header *string
+	for _, r := range results {
+		domain := strings.ToLower(r.res.Domain)
+		if hidden(r.res, r.err) {
+			report(r.res, r.err)
+			continue
+		}
+		if plainText() && (header == nil || domain != *header) {
+			printDomainHeader(r.res)
+			header = &domain
+		}
+		report(r.res, r.err)
+		if plainText() {
+			fmt.Println()
+		}
+	}
+}
+
+// printDomainHeader prints the domain of a group and its MX and mail
+// policy details
+func printDomainHeader(res verifier.Result) {
+	if res.Domain == "" {
+		color.Cyan("━━ Invalid addresses")
+		return
+	}
+	color.Cyan("━━ %s", res.Domain)
+	if len(res.MXRecords) > 0 {
+		color.Cyan("   📮 MX: %s", strings.Join(res.MXRecords, ", "))
+	}
+	if res.SPF != "" {
+		color.Cyan("   📜 SPF policy: %s", res.SPF)
+	}
+	if res.DMARC != "" {
+		color.Cyan("   🛡️ DMARC policy: %s", res.DMARC)
+	}
+	if res.MTASTS {
+		color.Cyan("   🔐 MTA-STS policy published")
+	}
+	if res.TLSRP {
+		color.Cyan("   📨 TLS-RPT reporting enabled")
+	}
+	fmt.Println()
+}
@@ -2,116 +2,595 @@ package main
 
 import (
 	"bufio"
-	"crypto/tls"
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
-	"net"
-	"net/mail"
-	"net/smtp"
+	"io"
+	"log/slog"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"email-verifier/verifier"
+
 	"github.com/fatih/color"
 )
 
-// isValidEmail checks the syntax of an email address
-func isValidEmail(email string) bool {
-	_, err := mail.ParseAddress(email)
-	return err == nil
+// options configures the library; flags are mapped onto it in main
+var options = verifier.DefaultOptions()
+
+// checker runs every verification, built from options once flags are parsed
+var checker *verifier.Verifier
+
+// minScore drops addresses scoring below it from file output
+var minScore int
+
+// b2bOnly drops addresses at consumer email providers from file output
+var b2bOnly bool
+
+// noDedup verifies every line of a file even when an address repeats
+var noDedup bool
+
+// dedupCanonical compares canonical forms when removing duplicates, so
+// provider aliases such as dotted Gmail addresses count as the same
+var dedupCanonical bool
+
+// occurrences counts how often each deduplicated email appeared in the
+// input, keyed by dedupKey
+var occurrences map[string]int
+
+// jsonOutput switches result output from colored text to JSON lines
+var jsonOutput bool
+
+// output receives the JSON or CSV results: stdout, or the -out file
+var output io.Writer = os.Stdout
+
+// jsonResult is the machine-readable form of a verification result
+type jsonResult struct {
+	Email            string `json:"email"`
+	Normalized       bool   `json:"normalized,omitempty"`
+	Canonical        string `json:"canonical,omitempty"`
+	ValidSyntax      bool   `json:"valid_syntax"`
+	Domain           string `json:"domain"`
+	ASCIIDomain      string `json:"ascii_domain,omitempty"`
+	DidYouMean       string `json:"did_you_mean,omitempty"`
+	Suspicious       bool   `json:"suspicious"`
+	SuspiciousReason string `json:"suspicious_reason,omitempty"`
+	Disposable       bool   `json:"disposable"`
+	RoleBased        bool   `json:"role_based"`
+	FreeProvider     bool   `json:"free_provider"`
+	Gravatar         bool   `json:"gravatar"`
+	DomainAgeDays    *int   `json:"domain_age_days,omitempty"`
+	MXFound          bool   `json:"mx_found"`
+	ImplicitMX       bool   `json:"implicit_mx"`
+	SPF              string `json:"spf,omitempty"`
+	DMARC            string `json:"dmarc,omitempty"`
+	MTASTS           bool   `json:"mta_sts"`
+	TLSRP            bool   `json:"tls_rpt"`
+	MXHasPTR         *bool  `json:"mx_has_ptr,omitempty"`
+	SMTPOK           *bool  `json:"smtp_ok"`
+	SMTPPort         int    `json:"smtp_port,omitempty"`
+	SMTPCode         int    `json:"smtp_code,omitempty"`
+	SMTPMessage      string `json:"smtp_message,omitempty"`
+	TLSVersion       string `json:"tls_version,omitempty"`
+	TLSValid         bool   `json:"tls_valid"`
+	TLSExpiry        string `json:"tls_expiry,omitempty"`
+	TLSFailed        bool   `json:"tls_failed,omitempty"`
+	VRFYCode         int    `json:"vrfy_code,omitempty"`
+	VRFYMessage      string `json:"vrfy_message,omitempty"`
+	CatchAll         bool   `json:"catch_all"`
+	PlusAddressing   *bool  `json:"plus_addressing,omitempty"`
+	Timeout          bool   `json:"timeout"`
+	Status           string `json:"status"`
+	Score            int    `json:"score"`
+	Occurrences      int    `json:"occurrences,omitempty"`
+	Reason           string `json:"reason,omitempty"`
+	Error            string `json:"error,omitempty"`
 }
 
-// getMXRecords retrieves MX records for the domain
-func getMXRecords(domain string) ([]*net.MX, error) {
-	mxRecords, err := net.LookupMX(domain)
-	if err != nil {
-		return nil, err
+// Exit codes reported by the CLI
+const (
+	exitOK        = 0
+	exitFailed    = 1
+	exitTransient = 2
+)
+
+// runStats tracks outcomes across every email verified in this run
+type runStats struct {
+	start         time.Time
+	total         int
+	deliverable   int
+	catchAll      int
+	undeliverable int
+	transient     int
+	unknown       int
+	skipped       int // -syntax-only or -no-smtp left the mailbox unchecked
+	disposable    int
+	duplicates    int
+	resumed       int
+	unprocessed   int
+	population    int // emails a -sample run chose from
+}
+
+// record adds a verification result to the run totals
+func (s *runStats) record(res verifier.Result, err error) {
+	s.total++
+	switch outcome(res, err) {
+	case "deliverable":
+		s.deliverable++
+	case "skipped":
+		s.skipped++
+	case "catch_all":
+		s.catchAll++
+	case "error":
+		s.transient++
+	case "unknown":
+		s.unknown++
+	default:
+		s.undeliverable++
+	}
+	if res.Disposable {
+		s.disposable++
 	}
-	return mxRecords, nil
 }
 
-// checkSMTP verifies if the email exists using an SMTP connection
-func checkSMTP(email, domain string) bool {
-	mxRecords, err := getMXRecords(domain)
-	if err != nil || len(mxRecords) == 0 {
-		color.Red("❌ No valid mail server found for domain: %s", domain)
-		return false
+// exitCode maps the run totals to a process exit status: any definite
+// failure wins over transient errors, unknown verdicts and emails left
+// unprocessed. Skipped SMTP checks confirm nothing either way and do not
+// count.
+func (s *runStats) exitCode() int {
+	switch {
+	case s.undeliverable > 0:
+		return exitFailed
+	case s.transient > 0, s.unknown > 0, s.unprocessed > 0:
+		return exitTransient
+	default:
+		return exitOK
 	}
+}
 
-	// Connect to the first mail server
-	mx := mxRecords[0].Host
-	color.Cyan("🔍 Checking SMTP server: %s", mx)
+// printSummary prints the run totals and how long the run took
+func (s *runStats) printSummary() {
+	elapsed := time.Since(s.start).Round(time.Millisecond)
+	color.Cyan("📊 Checked %d emails in %s", s.total, elapsed)
+	color.Green("   ✅ Valid:     %d", s.deliverable)
+	color.Red("   ❌ Invalid:   %d", s.undeliverable)
+	color.Yellow("   ⚠️ Catch-all: %d", s.catchAll)
+	color.Yellow("   ❓ Unknown:   %d", s.unknown)
+	color.Magenta("   💥 Errors:    %d", s.transient)
+	if s.skipped > 0 {
+		color.Cyan("   ⏭️ SMTP check skipped: %d", s.skipped)
+	}
+	if s.duplicates > 0 {
+		color.Cyan("   🔁 Duplicates skipped: %d", s.duplicates)
+	}
+	if s.resumed > 0 {
+		color.Cyan("   ⏭️ Already done (checkpoint): %d", s.resumed)
+	}
+	if s.unprocessed > 0 {
+		color.Yellow("   ⏳ Not processed: %d", s.unprocessed)
+	}
+	if s.population > 0 && s.total > 0 {
+		valid := 100 * float64(s.deliverable) / float64(s.total)
+		invalid := 100 * float64(s.undeliverable) / float64(s.total)
+		color.Cyan("📈 Sampled %d of %d emails: an estimated %.1f%% valid and %.1f%% invalid", s.total, s.population, valid, invalid)
+	}
+}
 
-	conn, err := net.DialTimeout("tcp", mx+":25", 5*time.Second)
-	if err != nil {
-		color.Red("❌ Failed to connect to mail server: %v", err)
-		return false
+// stats holds the totals for the current run
+var stats = runStats{start: time.Now()}
+
+// verifyEmail runs all checks on a single email and prints the outcome
+func verifyEmail(email string) {
+	res, err := verify(runCtx, email)
+	report(res, err)
+}
+
+// slots, when set, bounds the number of verifications running at once
+// across the whole process, and with it the number of SMTP connections
+var slots chan struct{}
+
+// verify runs the library checks on an email, reusing a cached result when
+// there is one
+func verify(ctx context.Context, email string) (verifier.Result, error) {
+	if slots != nil {
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+		case <-ctx.Done():
+			return verifier.Result{Email: email, Reason: "verification cancelled"}, ctx.Err()
+		}
+	}
+	if cache != nil {
+		if res, ok := cache.get(email); ok {
+			return res, nil
+		}
 	}
-	defer conn.Close()
+	start := time.Now()
+	res, err := checker.Verify(ctx, email)
+	observe(res, err, time.Since(start))
 
-	client, err := smtp.NewClient(conn, mx)
-	if err != nil {
-		color.Red("❌ Failed to create SMTP client: %v", err)
+	// Only definite outcomes are worth remembering; transient errors,
+	// unknown verdicts and skipped checks should be retried next run
+	if cache != nil && cacheable(res, err) {
+		cache.put(email, res)
+	}
+	return res, err
+}
+
+// quiet prints only failed results and the summary
+var quiet bool
+
+// hidden reports whether -quiet leaves a result out of the output because
+// the mailbox was not found to be bad
+func hidden(res verifier.Result, err error) bool {
+	if !quiet {
 		return false
 	}
-	defer client.Close()
+	o := outcome(res, err)
+	return o == "deliverable" || o == "skipped" || o == "catch_all" || o == "unknown"
+}
+
+// report records a result in the run totals and prints it, unless it is
+// hidden by -quiet
+func report(res verifier.Result, err error) {
+	stats.record(res, err)
+	if hidden(res, err) {
+		return
+	}
+	if jsonOutput {
+		printJSON(res)
+		return
+	}
+	if outputFormat != nil {
+		printFormatted(res)
+		return
+	}
+	printResult(res)
+}
+
+// indexedResult carries a result along with its position in the input
+type indexedResult struct {
+	index int
+	res   verifier.Result
+	err   error
+}
+
+// verifyAll verifies emails from the channel using a pool of workers and
+// hands each result to handle in the order the emails were received.
+// handle is always called from the calling goroutine.
+func verifyAll(ctx context.Context, emails <-chan string, workers int, handle func(verifier.Result, error)) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	type job struct {
+		index int
+		email string
+	}
+	jobs := make(chan job)
+	results := make(chan indexedResult)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				res, err := verify(ctx, j.email)
+				results <- indexedResult{index: j.index, res: res, err: err}
+			}
+		}()
+	}
+
+	// Dispatching stops early once the run is interrupted; the emails left
+	// over are counted as unprocessed
+	go func() {
+		index := 0
+	dispatch:
+		for email := range emails {
+			select {
+			case jobs <- job{index: index, email: email}:
+				index++
+			case <-stopping:
+				stats.unprocessed++
+				break dispatch
+			}
+		}
+		close(jobs)
+		if interrupted() {
+			countUnprocessed(emails)
+		}
+		wg.Wait()
+		close(results)
+	}()
+
+	// Buffer out-of-order results so output follows input order
+	pending := make(map[int]indexedResult)
+	next := 0
+	for r := range results {
+		pending[r.index] = r
+		for {
+			r, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			handle(r.res, r.err)
+			next++
+		}
+	}
+}
 
-	// Try TLS if supported
-	if ok, _ := client.Extension("STARTTLS"); ok {
-		tlsConfig := &tls.Config{InsecureSkipVerify: true, ServerName: mx}
-		if err = client.StartTLS(tlsConfig); err != nil {
-			color.Red("❌ Failed to start TLS: %v", err)
-			return false
+// countUnprocessed drains the emails left after the run stopped early,
+// counting them, unless a second signal aborts the run first
+func countUnprocessed(emails <-chan string) {
+	for {
+		select {
+		case _, ok := <-emails:
+			if !ok {
+				return
+			}
+			stats.unprocessed++
+		case <-runCtx.Done():
+			return
 		}
 	}
+}
 
-	// Use a fake sender email
-	fakeSender := "verify@example.com"
-	if err = client.Mail(fakeSender); err != nil {
-		color.Red("❌ MAIL FROM command failed: %v", err)
-		return false
+// printJSON writes a verification result to stdout as a single JSON line
+func printJSON(res verifier.Result) {
+	if err := json.NewEncoder(output).Encode(toJSONResult(res)); err != nil {
+		color.Red("❌ Failed to encode result: %v", err)
 	}
+}
 
-	// Check recipient email
-	if err = client.Rcpt(email); err != nil {
-		color.Red("❌ Email does not exist: %v", err)
-		return false
+// toJSONResult converts a library result to its machine-readable form
+func toJSONResult(res verifier.Result) jsonResult {
+	return jsonResult{
+		Email:            res.Email,
+		Normalized:       res.Normalized,
+		Canonical:        res.Canonical,
+		ValidSyntax:      res.SyntaxValid,
+		Domain:           res.Domain,
+		ASCIIDomain:      res.ASCIIDomain,
+		DidYouMean:       res.DidYouMean,
+		Suspicious:       res.Suspicious,
+		SuspiciousReason: res.SuspiciousReason,
+		Disposable:       res.Disposable,
+		RoleBased:        res.RoleBased,
+		FreeProvider:     res.FreeProvider,
+		Gravatar:         res.Gravatar,
+		DomainAgeDays:    domainAge(res),
+		MXFound:          len(res.MXRecords) > 0,
+		ImplicitMX:       res.ImplicitMX,
+		SPF:              res.SPF,
+		DMARC:            res.DMARC,
+		MTASTS:           res.MTASTS,
+		TLSRP:            res.TLSRP,
+		MXHasPTR:         mxHasPTR(res),
+		SMTPOK:           smtpOK(res),
+		SMTPPort:         res.SMTPPort,
+		SMTPCode:         res.SMTPCode,
+		SMTPMessage:      res.SMTPMessage,
+		TLSVersion:       res.TLSVersion,
+		TLSValid:         res.TLSValid,
+		TLSExpiry:        formatTime(res.TLSExpiry),
+		TLSFailed:        res.TLSFailed,
+		VRFYCode:         res.VRFYCode,
+		VRFYMessage:      res.VRFYMessage,
+		CatchAll:         res.CatchAll,
+		PlusAddressing:   plusAddressing(res),
+		Timeout:          res.Timeout,
+		Status:           string(res.Status),
+		Score:            res.Score,
+		Occurrences:      occurrences[dedupKey(res.Email)],
+		Reason:           res.Reason,
+		Error:            errorText(res),
 	}
+}
 
-	color.Green("✅ Email exists: %s", email)
-	return true
+// errorText is the reason a result failed, or empty for deliverable and
+// skipped results whose reason is not an error
+func errorText(res verifier.Result) string {
+	if res.Status == verifier.Deliverable || res.Status == verifier.Skipped {
+		return ""
+	}
+	return res.Reason
 }
 
-// verifyEmail performs syntax, MX record, and SMTP checks
-func verifyEmail(email string) {
-	if !isValidEmail(email) {
-		color.Red("❌ Invalid email format: %s", email)
-		return
+// domainAge is the age of the domain in days, or nil when unknown
+func domainAge(res verifier.Result) *int {
+	if res.DomainCreated.IsZero() {
+		return nil
+	}
+	return &res.DomainAgeDays
+}
+
+// smtpOK reports whether the mailbox was accepted, or nil when the SMTP
+// check was skipped and nothing is known
+func smtpOK(res verifier.Result) *bool {
+	if res.Status == verifier.Skipped {
+		return nil
+	}
+	ok := res.Status == verifier.Deliverable || res.Status == verifier.RiskyCatchAll
+	return &ok
+}
+
+// mxHasPTR reports whether the mail server has matching reverse DNS, or
+// nil when -check-ptr is off or the domain has no mail server
+func mxHasPTR(res verifier.Result) *bool {
+	if !options.CheckPTR || len(res.MXRecords) == 0 {
+		return nil
+	}
+	return &res.MXHasPTR
+}
+
+// plusAddressing reports whether the domain accepted a +tag version of the
+// mailbox, or nil when -check-plus is off or the probe did not run
+func plusAddressing(res verifier.Result) *bool {
+	if !options.CheckPlusAddressing || res.Status != verifier.Deliverable || res.AllowListed {
+		return nil
 	}
+	return &res.PlusAddressing
+}
 
-	// Extract domain
-	parts := strings.Split(email, "@")
-	if len(parts) != 2 {
-		color.Red("❌ Invalid email format: %s", email)
+// formatTime renders t as RFC 3339, or an empty string for the zero time
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// newDomainDays is the domain age below which a registration is flagged as
+// recent
+const newDomainDays = 30
+
+// printResult renders a verification result as colored text
+func printResult(res verifier.Result) {
+	if n := occurrences[dedupKey(res.Email)]; n > 1 {
+		color.Cyan("🔁 Appeared %d times in input: %s", n, res.Email)
+	}
+	if res.Normalized {
+		color.Yellow("🧹 Removed invisible characters or surrounding spaces from: %q", res.Email)
+	}
+	if !res.SyntaxValid {
+		color.Red("❌ Invalid email format: %s", res.Email)
+		if detail := strings.TrimPrefix(res.Reason, "invalid email format: "); detail != res.Reason {
+			color.Red("   %s", detail)
+		}
+		return
+	}
+	defer color.Cyan("🎯 Score: %d/100", res.Score)
+	if res.DidYouMean != "" {
+		color.Yellow("💡 Did you mean %s?", res.DidYouMean)
+	}
+	if res.Suspicious {
+		color.Yellow("🎭 Lookalike characters: %s", res.SuspiciousReason)
+	}
+	if res.Disposable {
+		color.Yellow("⚠️ Disposable email provider: %s", res.Domain)
+	}
+	if res.RoleBased {
+		color.Yellow("⚠️ Role-based address: %s", res.Email)
+	}
+	if res.FreeProvider {
+		color.Cyan("ℹ️ Free email provider: %s", res.Domain)
+	}
+	if res.DenyListed {
+		color.Red("❌ Domain is on the deny list: %s", res.Domain)
+		return
+	}
+	if res.Reserved {
+		color.Red("❌ %s is reserved for documentation and testing and never receives mail", res.Domain)
+		return
+	}
+	if res.AllowListed {
+		color.Green("✅ Domain is on the allow list: %s", res.Domain)
+		return
+	}
+	if options.SyntaxOnly {
+		color.Green("✔️ Valid email format: %s", res.Email)
+		return
+	}
+	if res.Gravatar {
+		color.Cyan("🖼️ Gravatar profile found")
+	}
+	if !res.DomainCreated.IsZero() {
+		if res.DomainAgeDays < newDomainDays {
+			color.Yellow("⚠️ Domain registered only %d days ago (%s)", res.DomainAgeDays, res.DomainCreated.Format("2006-01-02"))
+		} else {
+			color.Cyan("📅 Domain registered %s (%d days ago)", res.DomainCreated.Format("2006-01-02"), res.DomainAgeDays)
+		}
+	} else if res.DomainAgeDays < 0 {
+		color.Cyan("📅 Domain registration date unknown")
+	}
+	if res.Status == verifier.NoDomain {
+		color.Red("❌ Domain does not exist: %s", res.Domain)
+		return
+	}
+	if len(res.MXRecords) == 0 {
+		color.Red("❌ No valid mail server found for domain: %s", res.Domain)
+		color.Red("   %s", res.Reason)
 		return
 	}
-	domain := parts[1]
 
-	// Check MX records
-	mxRecords, err := getMXRecords(domain)
-	if err != nil || len(mxRecords) == 0 {
-		color.Red("❌ No valid mail server found for domain: %s", domain)
+	color.Green("✔️ Valid email format and domain exists: %s", res.Email)
+	if res.ImplicitMX {
+		color.Yellow("⚠️ No MX records, using the domain's own address as mail server")
+	}
+	if res.SPF != "" && !groupByDomain {
+		color.Cyan("📜 SPF policy: %s", res.SPF)
+	}
+	if res.DMARC != "" && !groupByDomain {
+		color.Cyan("🛡️ DMARC policy: %s", res.DMARC)
+	}
+	if res.MTASTS && !groupByDomain {
+		color.Cyan("🔐 MTA-STS policy published")
+	}
+	if res.TLSRP && !groupByDomain {
+		color.Cyan("📨 TLS-RPT reporting enabled")
+	}
+	if options.CheckPTR && !groupByDomain {
+		if res.MXHasPTR {
+			color.Cyan("🔁 Mail server has matching reverse DNS")
+		} else {
+			color.Yellow("⚠️ Mail server has no matching reverse DNS (PTR)")
+		}
+	}
+	if res.Status == verifier.Skipped {
+		color.Cyan("⏭️ SMTP check skipped")
 		return
 	}
+	if res.SMTPServer != "" {
+		color.Cyan("🔍 Checking SMTP server: %s (port %d)", res.SMTPServer, res.SMTPPort)
+	}
+	if res.TLSVersion != "" {
+		if res.TLSValid {
+			color.Cyan("🔒 %s, certificate valid until %s", res.TLSVersion, res.TLSExpiry.Format("2006-01-02"))
+		} else {
+			color.Yellow("🔓 %s, certificate invalid (expires %s)", res.TLSVersion, res.TLSExpiry.Format("2006-01-02"))
+		}
+	}
+	if res.TLSFailed {
+		color.Yellow("🔓 STARTTLS failed, checked over plaintext instead")
+	}
+	if res.VRFYCode != 0 {
+		color.Cyan("🔎 VRFY answered %d: %s", res.VRFYCode, res.VRFYMessage)
+	}
 
-	color.Green("✔️ Valid email format and domain exists: %s", email)
+	if res.PlusAddressing {
+		color.Cyan("➕ Domain supports plus addressing")
+	}
 
-	// Check if email exists via SMTP
-	checkSMTP(email, domain)
+	switch res.Status {
+	case verifier.Deliverable:
+		color.Green("✅ Email exists: %s", res.Email)
+	case verifier.RiskyCatchAll:
+		color.Yellow("⚠️ Domain accepts all recipients, existence unknown: %s", res.Email)
+	case verifier.Unknown:
+		color.Yellow("⚠️ %s", res.Reason)
+	default:
+		color.Red("❌ %s", res.Reason)
+	}
 }
 
-// processFile reads emails from a file and verifies them
+// processFile reads emails from a file and verifies them; a path of "-"
+// reads from stdin and an http(s) URL is fetched
 func processFile(filePath string) {
+	if filePath == "-" {
+		processReader(os.Stdin, 0)
+		return
+	}
+	if isURL(filePath) {
+		processURL(filePath)
+		return
+	}
+
 	file, err := os.Open(filePath)
 	if err != nil {
 		color.Red("❌ Failed to open file: %v", err)
@@ -119,41 +598,515 @@ func processFile(filePath string) {
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
+	total := countLines(filePath)
+	if csvMode && total > 0 {
+		total-- // header row
+	}
+	processReader(file, total)
+}
+
+// filtered reports whether a result is left out of file output by the
+// -b2b-only or -min-score filters
+func filtered(res verifier.Result) bool {
+	return (b2bOnly && res.FreeProvider) || res.Score < minScore
+}
+
+// readUnique reads every email from scanner, keeping the first occurrence
+// of each address and counting repeats in occurrences
+func readUnique(scanner *bufio.Scanner) []string {
+	occurrences = make(map[string]int)
+	var unique []string
 	for scanner.Scan() {
 		email := strings.TrimSpace(scanner.Text())
-		if email != "" {
-			verifyEmail(email)
-			fmt.Println()
+		if email == "" || resumed(email) {
+			continue
+		}
+		key := dedupKey(email)
+		if occurrences[key] == 0 {
+			unique = append(unique, email)
+		} else {
+			stats.duplicates++
 		}
+		occurrences[key]++
+	}
+	return unique
+}
+
+// dedupKey is the form of an email compared when removing duplicates
+func dedupKey(email string) string {
+	if dedupCanonical {
+		return verifier.Canonicalize(email)
+	}
+	return strings.ToLower(email)
+}
+
+// processReader verifies each non-empty line read from r; total is the
+// expected number of emails for the progress line, or zero if unknown
+func processReader(r io.Reader, total int) {
+	if skipLines > 0 {
+		r = skipLeading(r, skipLines)
+		total = max(total-skipLines, 0)
+	}
+	if csvMode {
+		processCSV(r, total)
+		return
 	}
 
+	emails := make(chan string)
+	scanner := bufio.NewScanner(r)
+	if sampleSize > 0 {
+		sample := readSample(scanner)
+		total = len(sample)
+		go func() {
+			defer close(emails)
+			for _, email := range sample {
+				emails <- email
+			}
+		}()
+	} else if noDedup {
+		go func() {
+			defer close(emails)
+			for scanner.Scan() {
+				email := strings.TrimSpace(scanner.Text())
+				if email != "" && !resumed(email) {
+					emails <- email
+				}
+			}
+		}()
+	} else {
+		unique := readUnique(scanner)
+		total = len(unique)
+		go func() {
+			defer close(emails)
+			for _, email := range unique {
+				emails <- email
+			}
+		}()
+	}
+
+	verifyStream(emails, total)
 	if err := scanner.Err(); err != nil {
-		color.Red("❌ Error reading file: %v", err)
+		color.Red("❌ Error reading input: %v", err)
+	}
+	stats.printSummary()
+}
+
+// skipLines is the number of leading lines of file input to ignore, such as
+// a header or metadata in a plain list
+var skipLines int
+
+// skipLeading discards the first n lines of r
+func skipLeading(r io.Reader, n int) io.Reader {
+	br := bufio.NewReader(r)
+	for i := 0; i < n; i++ {
+		if _, err := br.ReadString('\n'); err != nil {
+			break
+		}
+	}
+	return br
+}
+
+// verifyList verifies several emails given on the command line with the
+// worker pool, reporting them in the order given
+func verifyList(list []string) {
+	emails := make(chan string)
+	go func() {
+		defer close(emails)
+		for _, email := range list {
+			emails <- email
+		}
+	}()
+	verifyStream(emails, len(list))
+	stats.printSummary()
+}
+
+// verifyStream verifies every email received from emails and reports the
+// results in order; total sizes the progress line, or is zero if unknown.
+// With -greylist-pass, greylisted emails are verified again after a delay
+// and reported at the end.
+func verifyStream(emails <-chan string, total int) {
+	var grouped []indexedResult
+	emit := func(res verifier.Result, err error) {
+		if filtered(res) {
+			markDone(res, err)
+			return
+		}
+		if groupByDomain {
+			grouped = append(grouped, indexedResult{res: res, err: err})
+			return
+		}
+		report(res, err)
+		if plainText() && !hidden(res, err) {
+			fmt.Println()
+		}
+		markDone(res, err)
+	}
+
+	var deferred []indexedResult
+	bar := newProgress(total)
+	verifyAll(runCtx, emails, options.Concurrency, func(res verifier.Result, err error) {
+		bar.clear()
+		defer bar.advance()
+		if greylistPass && greylisted(res) {
+			deferred = append(deferred, indexedResult{res: res, err: err})
+			return
+		}
+		emit(res, err)
+	})
+	bar.clear()
+
+	if len(deferred) > 0 {
+		retryGreylisted(deferred, emit)
+	}
+	printGrouped(grouped)
+	for _, r := range grouped {
+		markDone(r.res, r.err)
+	}
+}
+
+// stringList is a flag that collects every value it is given, so it can
+// be repeated on the command line
+type stringList []string
+
+func (l *stringList) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// traceMu keeps lines of concurrent SMTP conversations from interleaving
+var traceMu sync.Mutex
+
+// traceSMTP prints one line of an SMTP conversation to stderr, green for
+// what we sent and cyan for what the server replied
+func traceSMTP(host string, sent bool, line string) {
+	traceMu.Lock()
+	defer traceMu.Unlock()
+	if sent {
+		color.New(color.FgGreen).Fprintf(os.Stderr, "%s → %s\n", host, line)
+		return
+	}
+	color.New(color.FgCyan).Fprintf(os.Stderr, "%s ← %s\n", host, line)
+}
+
+// warnIfPort25Blocked tells the user up front when SMTP checks cannot work
+// from this network
+func warnIfPort25Blocked() {
+	if options.SyntaxOnly || options.SkipSMTP || options.Server != "" || options.Port != 25 {
+		return
+	}
+	if checker.Port25Blocked(context.Background()) {
+		color.Yellow("⚠️ Outbound port 25 appears blocked by your network; SMTP checks will be unreliable")
+		color.Yellow("   Use -no-smtp to stop after the DNS checks, or -proxy to connect through another host")
+	}
+}
+
+// warnIfBadSender tells the user when the MAIL FROM domain is one that
+// mail servers are likely to reject, such as an unqualified hostname
+func warnIfBadSender() {
+	if options.SyntaxOnly || options.SkipSMTP {
+		return
+	}
+	sender := checker.Sender()
+	domain := sender[strings.LastIndex(sender, "@")+1:]
+	if !strings.Contains(domain, ".") || verifier.IsReservedDomain(domain) {
+		color.Yellow("⚠️ MAIL FROM %s is not at a real domain; many servers will reject it", sender)
+		color.Yellow("   Use -from or -from-domain to set a sender at a domain you control")
 	}
 }
 
 func main() {
 	// Command-line arguments
-	singleEmail := flag.String("email", "", "Email address to verify")
-	filePath := flag.String("file", "", "Path to a file containing emails (one per line)")
+	configFile := flag.String("config", "", "JSON file of settings keyed by flag name; flags on the command line take precedence")
+	var emailList stringList
+	flag.Var(&emailList, "email", "Email address to verify; repeat to verify several")
+	flag.Func("emails", "Comma-separated email addresses to verify", func(value string) error {
+		for _, email := range strings.Split(value, ",") {
+			if email = strings.TrimSpace(email); email != "" {
+				emailList = append(emailList, email)
+			}
+		}
+		return nil
+	})
+	filePath := flag.String("file", "", "Path or http(s) URL of a file containing emails (one per line), or - for stdin")
+	readStdin := flag.Bool("stdin", false, "Read emails from stdin (one per line)")
+	flag.StringVar(&options.Server, "server", "", "Mail server (host:port) to check against instead of the domain's MX hosts")
+	flag.IntVar(&options.MaxMX, "max-mx", options.MaxMX, "Most MX hosts to try per domain, best preference first (0 tries all)")
+	flag.IntVar(&options.Port, "port", options.Port, "SMTP port to connect to")
+	flag.IntVar(&options.RcptPerConn, "rcpt-per-conn", 0, "RCPT TO commands per SMTP connection, reusing connections for addresses at the same domain (0 opens one per address)")
+	flag.BoolVar(&options.PortFallback, "port-fallback", false, "Fall back to ports 587 and 465 when the SMTP port times out")
+	flag.StringVar(&options.FromAddr, "from", "", "MAIL FROM address (default verify@<from-domain>)")
+	flag.StringVar(&options.FromDomain, "from-domain", options.FromDomain, "Domain used to build the default MAIL FROM address (default the HELO hostname)")
+	rotateFrom := flag.String("rotate-from", "", "MAIL FROM local parts to cycle through, one per connection, comma-separated or a file with one per line")
+	rotateHelo := flag.String("rotate-helo", "", "EHLO/HELO hostnames to cycle through, one per connection, comma-separated or a file with one per line")
+	flag.StringVar(&options.HeloName, "helo", "", "Hostname to send with EHLO/HELO (default local hostname)")
+	flag.IntVar(&options.ConnectRetries, "retry-on-connection-error", 0, "Times to retry connecting to a mail server after a reset or timeout")
+	flag.DurationVar(&options.ConnectBackoff, "connect-backoff", options.ConnectBackoff, "Delay before the first connection retry, doubling on each attempt")
+	flag.IntVar(&options.Retries, "retries", options.Retries, "Times to retry RCPT TO after a transient 4xx reply")
+	allowDomains := flag.String("allow-domains", "", "Domains to treat as deliverable without checking, comma-separated or a file with one per line")
+	denyDomains := flag.String("deny-domains", "", "Domains to reject without checking, comma-separated or a file with one per line")
+	noDisposable := flag.Bool("no-disposable", false, "Exit non-zero if any email uses a disposable provider")
+	rolesFile := flag.String("roles-file", "", "File of role account local parts (one per line) replacing the built-in list")
+	flag.BoolVar(&b2bOnly, "b2b-only", false, "Leave addresses at free email providers out of file output")
+	flag.BoolVar(&options.CheckDomainAge, "whois", false, "Look up the domain's registration date over RDAP")
+	flag.BoolVar(&options.CheckPTR, "check-ptr", false, "Check that the domain's mail server has matching reverse DNS (PTR)")
+	flag.BoolVar(&options.CheckGravatar, "gravatar", false, "Check whether the email has a Gravatar profile image")
+	flag.Func("tls", "STARTTLS policy: opportunistic (default), required or none", func(value string) error {
+		switch value {
+		case "opportunistic":
+			options.TLSPolicy = verifier.TLSOpportunistic
+		case "required":
+			options.TLSPolicy = verifier.TLSRequired
+		case "none":
+			options.TLSPolicy = verifier.TLSNone
+		default:
+			return fmt.Errorf("unknown TLS policy %q", value)
+		}
+		return nil
+	})
+	flag.BoolVar(&options.StrictTLS, "strict-tls", false, "Verify mail server TLS certificates and skip servers with invalid ones")
+	flag.BoolFunc("prefer-ipv4", "Try a mail server's IPv4 addresses before IPv6", func(value string) error {
+		on, err := strconv.ParseBool(value)
+		if on {
+			options.PreferFamily = verifier.PreferIPv4
+		}
+		return err
+	})
+	flag.BoolFunc("prefer-ipv6", "Try a mail server's IPv6 addresses before IPv4 (the default)", func(value string) error {
+		on, err := strconv.ParseBool(value)
+		if on {
+			options.PreferFamily = verifier.PreferIPv6
+		}
+		return err
+	})
+	flag.StringVar(&options.ProxyURL, "proxy", "", "SOCKS5 proxy for SMTP connections, e.g. socks5://host:1080")
+	flag.BoolVar(&options.SyntaxOnly, "syntax-only", false, "Only check syntax and local signals, skipping all DNS and SMTP checks")
+	flag.BoolVar(&options.SkipSMTP, "no-smtp", false, "Check syntax and MX records only, never connecting to mail servers")
+	flag.BoolVar(&options.CheckPlusAddressing, "check-plus", false, "Also probe whether the domain accepts plus-addressed (local+tag@) variants of the mailbox")
+	flag.BoolVar(&options.TryVRFY, "try-vrfy", false, "Also ask servers that advertise VRFY to confirm the mailbox, as an extra signal")
+	skipCatchAll := flag.Bool("skip-catch-all", false, "Skip the catch-all domain probe")
+	flag.IntVar(&minScore, "min-score", 0, "Leave addresses scoring below this (0-100) out of file output")
+	flag.BoolVar(&groupByDomain, "group-by-domain", false, "Print file results grouped by domain, with shared MX/SPF/DMARC details once per domain")
+	flag.IntVar(&sampleSize, "sample", 0, "Verify only a random sample of this many emails from the file, to estimate list quality")
+	flag.Int64Var(&sampleSeed, "sample-seed", 0, "Seed for -sample, to pick the same sample again (0 picks a new one each run)")
+	flag.BoolVar(&greylistPass, "greylist-pass", false, "Verify greylisted (4xx) addresses again after -greylist-delay, reporting the second attempt")
+	flag.DurationVar(&greylistDelay, "greylist-delay", greylistDelay, "How long -greylist-pass waits before the second attempt")
+	flag.IntVar(&skipLines, "skip-lines", 0, "Ignore this many lines at the start of the file, such as a header (with -csv, lines before the header row)")
+	flag.BoolVar(&noDedup, "no-dedup", false, "Verify repeated addresses in a file every time they appear")
+	flag.BoolVar(&dedupCanonical, "dedup-canonical", false, "Treat provider aliases (e.g. dotted Gmail addresses) as duplicates")
+	flag.IntVar(&options.Concurrency, "concurrency", options.Concurrency, "Number of emails to verify in parallel in file mode")
+	flag.Float64Var(&options.DomainRate, "domain-rate", 0, "Maximum SMTP probes per domain per second (0 for no limit)")
+	flag.StringVar(&options.DNSServer, "dns", "", "DNS resolver address to use instead of the system resolver, e.g. 8.8.8.8:53")
+	flag.IntVar(&options.DNSRetries, "dns-retries", options.DNSRetries, "Times to retry an MX lookup after a temporary DNS failure")
+	flag.DurationVar(&options.MXCacheTTL, "mx-cache-ttl", options.MXCacheTTL, "How long to reuse MX lookups, DNS policies and catch-all status for a domain (0 disables caching)")
+	flag.DurationVar(&options.DNSTimeout, "dns-timeout", options.DNSTimeout, "Maximum time for the DNS lookups of a domain (0 for no limit)")
+	flag.DurationVar(&options.ConnectTimeout, "connect-timeout", options.ConnectTimeout, "Maximum time to open a TCP connection to a mail server (0 for no limit)")
+	flag.DurationVar(&options.TLSTimeout, "tls-timeout", 0, "Maximum time for a TLS handshake (0 uses -smtp-timeout)")
+	flag.DurationVar(&options.CommandTimeout, "smtp-timeout", options.CommandTimeout, "Maximum time for each SMTP command to get a reply (0 for no limit)")
+	flag.DurationVar(&options.Timeout, "timeout", options.Timeout, "Maximum time to spend verifying each email (0 for no limit)")
+	serveMode := flag.Bool("serve", false, "Run an HTTP API server instead of verifying from the command line")
+	addr := flag.String("addr", ":8080", "Address for the HTTP API server to listen on")
+	flag.IntVar(&maxBatch, "max-batch", maxBatch, "Maximum number of emails accepted by POST /verify/batch")
+	flag.BoolVar(&csvMode, "csv", false, "Read input as CSV with a header row and write CSV results")
+	flag.StringVar(&emailColumn, "email-column", emailColumn, "CSV column holding the email, by header name or zero-based index")
+	logLevel := flag.String("log-level", "error", "Diagnostic log level written to stderr: debug, info, warn or error")
+	maxRuntime := flag.Duration("max-runtime", 0, "Stop starting new verifications after this long, finishing those in progress (0 means no limit)")
+	checkpointFile := flag.String("checkpoint", "", "File recording finished emails; a rerun with the same file skips them")
+	cacheFile := flag.String("cache-file", "", "JSON file caching results between runs")
+	flag.IntVar(&options.CacheSize, "cache-size", options.CacheSize, "Maximum entries kept in each in-memory cache (results, MX and domain lookups), evicting the least recently used (0 for no limit)")
+	cacheTTL := flag.Duration("cache-ttl", 24*time.Hour, "How long cached results stay valid")
+	flag.BoolVar(&reportMode, "report", false, "Print a detailed report of every check for a single -email")
+	checkDNSBL := flag.Bool("check-blacklist", false, "Check at startup whether our public IP is on common DNS blocklists")
+	noColor := flag.Bool("no-color", false, "Disable colored output (automatic when output is not a terminal)")
+	verbose := flag.Bool("verbose", false, "Print the full SMTP conversation to stderr")
+	flag.BoolVar(&quiet, "quiet", false, "Only print invalid, undeliverable and failed results, plus the summary")
+	outPath := flag.String("out", "", "Write JSON results (or CSV with -csv, or -format output) to this file, keeping messages and progress on stderr")
+	flag.BoolVar(&jsonOutput, "json", false, "Print results as JSON (one object per line)")
+	format := flag.String("format", "", "Print each result with this Go text/template, e.g. '{{.Email}},{{.Status}},{{.MXFound}}'")
 	flag.Parse()
 
+	if *configFile != "" {
+		if err := loadConfig(*configFile); err != nil {
+			color.Red("❌ Failed to load config: %v", err)
+			os.Exit(exitFailed)
+		}
+	}
+
+	options.CheckCatchAll = !*skipCatchAll
+	if *verbose {
+		options.Trace = traceSMTP
+	}
+	var err error
+	if options.AllowDomains, err = domainList(*allowDomains); err != nil {
+		color.Red("❌ Failed to load allowed domains: %v", err)
+		os.Exit(exitFailed)
+	}
+	if options.DenyDomains, err = domainList(*denyDomains); err != nil {
+		color.Red("❌ Failed to load denied domains: %v", err)
+		os.Exit(exitFailed)
+	}
+	if options.FromPool, err = domainList(*rotateFrom); err != nil {
+		color.Red("❌ Failed to load MAIL FROM local parts: %v", err)
+		os.Exit(exitFailed)
+	}
+	if options.HeloPool, err = domainList(*rotateHelo); err != nil {
+		color.Red("❌ Failed to load HELO hostnames: %v", err)
+		os.Exit(exitFailed)
+	}
+	checker = verifier.New(options)
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(*logLevel)); err != nil {
+		color.Red("❌ Invalid log level: %s", *logLevel)
+		os.Exit(exitFailed)
+	}
+	verifier.Logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+	if *rolesFile != "" {
+		if err := verifier.LoadRolesFile(*rolesFile); err != nil {
+			color.Red("❌ Failed to load roles file: %v", err)
+			os.Exit(exitFailed)
+		}
+	}
+
+	if *format != "" {
+		if outputFormat, err = parseFormat(*format); err != nil {
+			color.Red("❌ Invalid -format template: %v", err)
+			os.Exit(exitFailed)
+		}
+	}
+
+	var outFile *os.File
+	if *outPath != "" {
+		var err error
+		if outFile, err = os.Create(*outPath); err != nil {
+			color.Red("❌ Failed to create output file: %v", err)
+			os.Exit(exitFailed)
+		}
+		output = outFile
+		if !csvMode && *format == "" {
+			jsonOutput = true
+		}
+	}
+
+	// Keep stdout valid JSON or CSV by sending any colored messages to stderr
+	messages := os.Stdout
+	if !plainText() || csvMode {
+		messages = os.Stderr
+		color.Output = messages
+	}
+	// Escape codes only help a person watching a terminal; JSON and
+	// -format runs feed other programs, so they never get color
+	color.NoColor = *noColor || !plainText() || os.Getenv("NO_COLOR") != "" || !isTerminal(messages)
+
+	if *cacheFile != "" {
+		var err error
+		if cache, err = loadResultCache(*cacheFile, *cacheTTL, options.CacheSize); err != nil {
+			color.Red("❌ Failed to load cache file: %v", err)
+			os.Exit(exitFailed)
+		}
+	}
+	if *checkpointFile != "" {
+		var err error
+		if checkpoint, err = openCheckpoint(*checkpointFile); err != nil {
+			color.Red("❌ Failed to open checkpoint file: %v", err)
+			os.Exit(exitFailed)
+		}
+	}
+
+	// A report runs the blocklist check itself
+	if *checkDNSBL && !reportMode {
+		checkBlacklist()
+	}
+
+	if *serveMode {
+		warnIfPort25Blocked()
+		warnIfBadSender()
+		if err := serve(*addr); err != nil {
+			color.Red("❌ Server stopped: %v", err)
+			os.Exit(exitFailed)
+		}
+		return
+	}
+
+	if *readStdin {
+		*filePath = "-"
+	}
+	if reportMode && (len(emailList) != 1 || *filePath != "") {
+		color.Red("❌ -report needs exactly one -email and no file input")
+		os.Exit(exitFailed)
+	}
+	if sampleSize > 0 && csvMode {
+		color.Red("❌ -sample cannot be combined with -csv")
+		os.Exit(exitFailed)
+	}
+
 	// Ensure input is provided
-	if *singleEmail == "" && *filePath == "" {
+	if len(emailList) == 0 && *filePath == "" {
 		color.Yellow("Usage:")
-		color.Cyan("  go run main.go -email test@example.com")
-		color.Cyan("  go run main.go -file emails.txt")
-		os.Exit(1)
+		color.Cyan("  go run . -email jane.doe@gmail.com")
+		color.Cyan("  go run . -emails \"jane.doe@gmail.com, john.doe@outlook.com\"")
+		color.Cyan("  go run . -file emails.txt")
+		color.Cyan("  go run . -file emails.txt -json")
+		color.Cyan("  cat emails.txt | go run . -stdin")
+		color.Cyan("  go run . -file contacts.csv -csv -email-column email")
+		color.Cyan("  go run . -serve -addr :8080")
+		color.Cyan("  go run . -config verify.json -file emails.txt")
+		os.Exit(exitFailed)
+	}
+
+	warnIfPort25Blocked()
+	warnIfBadSender()
+	handleSignals()
+	if *maxRuntime > 0 {
+		limitRuntime(*maxRuntime)
 	}
 
 	// Verify single email
-	if *singleEmail != "" {
-		verifyEmail(*singleEmail)
+	switch len(emailList) {
+	case 0:
+	case 1:
+		if reportMode {
+			printReport(verify(runCtx, emailList[0]))
+			break
+		}
+		verifyEmail(emailList[0])
+	default:
+		verifyList(emailList)
 	}
 
-	// Verify emails from file
-	if *filePath != "" {
+	// Verify emails from file or stdin
+	if *filePath != "" && !interrupted() {
 		processFile(*filePath)
 	}
+
+	checker.Close()
+	if outFile != nil {
+		if err := outFile.Close(); err != nil {
+			color.Red("❌ Failed to write output file: %v", err)
+		}
+	}
+	if checkpoint != nil {
+		if err := checkpoint.close(); err != nil {
+			color.Red("❌ Failed to write checkpoint file: %v", err)
+		}
+	}
+	if cache != nil {
+		if err := cache.save(); err != nil {
+			color.Red("❌ Failed to save cache file: %v", err)
+		}
+	}
+
+	if signalled.Load() {
+		os.Exit(exitInterrupted)
+	}
+	if *noDisposable && stats.disposable > 0 {
+		os.Exit(exitFailed)
+	}
+	os.Exit(stats.exitCode())
 }
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"time"
+
+	"email-verifier/verifier"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Prometheus metrics exposed on /metrics in server mode
+var (
+	verificationsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "email_verifier_verifications_total",
+		Help: "Emails verified, excluding cached results.",
+	})
+	outcomesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "email_verifier_outcomes_total",
+		Help: "Verification outcomes: deliverable, undeliverable, catch_all, unknown, skipped or error.",
+	}, []string{"outcome"})
+	disposableTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "email_verifier_disposable_total",
+		Help: "Verified emails at disposable email providers.",
+	})
+	verificationSeconds = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "email_verifier_verification_duration_seconds",
+		Help:    "Time taken to verify one email.",
+		Buckets: prometheus.ExponentialBuckets(0.01, 2, 12),
+	})
+	smtpSeconds = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "email_verifier_smtp_duration_seconds",
+		Help:    "Time spent talking to mail servers for one email.",
+		Buckets: prometheus.ExponentialBuckets(0.01, 2, 12),
+	})
+)
+
+// outcome classifies a result the way the run summary counts it. A non-nil
+// err means a network step failed, which may succeed if retried later;
+// "unknown" means the checks completed without reaching a verdict, and
+// "skipped" that -syntax-only or -no-smtp left the mailbox unchecked.
+func outcome(res verifier.Result, err error) string {
+	switch {
+	case res.Status == verifier.Deliverable:
+		return "deliverable"
+	case res.Status == verifier.Skipped:
+		return "skipped"
+	case res.Status == verifier.RiskyCatchAll:
+		return "catch_all"
+	case err != nil:
+		return "error"
+	case res.Status == verifier.Unknown, res.Status == verifier.Timeout:
+		// No verdict, such as an accept-all provider or a server that does
+		// not offer the TLS we require
+		return "unknown"
+	default:
+		return "undeliverable"
+	}
+}
+
+// observe records a completed verification in the metrics
+func observe(res verifier.Result, err error, elapsed time.Duration) {
+	verificationsTotal.Inc()
+	outcomesTotal.WithLabelValues(outcome(res, err)).Inc()
+	if res.Disposable {
+		disposableTotal.Inc()
+	}
+	verificationSeconds.Observe(elapsed.Seconds())
+	if res.SMTPDuration > 0 {
+		smtpSeconds.Observe(res.SMTPDuration.Seconds())
+	}
+}
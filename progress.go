@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/mattn/go-isatty"
+)
+
+// progress draws an updating "done/total" line on stderr during bulk runs
+type progress struct {
+	enabled bool
+	total   int // zero when the number of emails is not known up front
+	done    int
+}
+
+// newProgress returns a progress line that only draws when stderr is a
+// terminal and output is not JSON on the same screen
+func newProgress(total int) *progress {
+	enabled := (plainText() || output != os.Stdout) && isTerminal(os.Stderr)
+	return &progress{enabled: enabled, total: total}
+}
+
+// clear erases the progress line so a result can be printed in its place
+func (p *progress) clear() {
+	if p.enabled {
+		fmt.Fprint(os.Stderr, "\r\033[K")
+	}
+}
+
+// advance counts one more finished email and redraws the line
+func (p *progress) advance() {
+	p.done++
+	if !p.enabled {
+		return
+	}
+	if p.total > 0 {
+		fmt.Fprintf(os.Stderr, "\r⏳ %d/%d", p.done, p.total)
+	} else {
+		fmt.Fprintf(os.Stderr, "\r⏳ %d", p.done)
+	}
+}
+
+// isTerminal reports whether f is attached to a terminal
+func isTerminal(f *os.File) bool {
+	fd := f.Fd()
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
+
+// countLines returns the number of non-empty lines in a file, or zero if
+// it cannot be read
+func countLines(path string) int {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	n := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) > 0 {
+			n++
+		}
+	}
+	return n
+}
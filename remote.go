@@ -0,0 +1,40 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// remoteClient fetches email lists from HTTP(S) URLs. Redirects are
+// followed; the timeout only covers waiting for the response headers so
+// long lists can stream for as long as verification takes.
+var remoteClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		ResponseHeaderTimeout: 30 * time.Second,
+	},
+}
+
+// isURL reports whether a -file argument names an HTTP(S) resource
+func isURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// processURL streams emails from an HTTP(S) URL and verifies them like a
+// local file
+func processURL(url string) {
+	resp, err := remoteClient.Get(url)
+	if err != nil {
+		color.Red("❌ Failed to fetch %s: %v", url, err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		color.Red("❌ Failed to fetch %s: %s", url, resp.Status)
+		return
+	}
+	processReader(resp.Body, 0)
+}
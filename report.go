@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"email-verifier/verifier"
+
+	"github.com/fatih/color"
+)
+
+// reportMode prints every signal gathered for a single -email as a
+// sectioned report instead of the usual summary
+var reportMode bool
+
+// printReport renders everything learned about one email, section by
+// section, for diagnosing a single address in depth
+func printReport(res verifier.Result, err error) {
+	stats.record(res, err)
+	bold := color.New(color.Bold)
+	bold.Printf("📋 Report for %s\n", res.Email)
+
+	reportSection("Syntax")
+	reportField("valid", yesNo(res.SyntaxValid))
+	if res.Normalized {
+		reportField("normalized", "removed invisible characters or surrounding spaces")
+	}
+	reportField("canonical", res.Canonical)
+	reportField("did you mean", res.DidYouMean)
+	if res.Suspicious {
+		reportField("lookalikes", res.SuspiciousReason)
+	}
+	reportField("role-based", yesNo(res.RoleBased))
+	if !res.SyntaxValid {
+		reportVerdict(res, err)
+		return
+	}
+
+	reportSection("Domain")
+	reportField("domain", res.Domain)
+	if res.ASCIIDomain != res.Domain {
+		reportField("punycode", res.ASCIIDomain)
+	}
+	reportField("disposable", yesNo(res.Disposable))
+	reportField("free provider", yesNo(res.FreeProvider))
+	reportField("reserved", yesNo(res.Reserved))
+	switch {
+	case res.AllowListed:
+		reportField("listed", "allowed by -allow-domains")
+	case res.DenyListed:
+		reportField("listed", "denied by -deny-domains")
+	}
+	switch {
+	case !res.DomainCreated.IsZero():
+		reportField("registered", fmt.Sprintf("%s (%d days ago)", res.DomainCreated.Format("2006-01-02"), res.DomainAgeDays))
+	case options.CheckDomainAge:
+		reportField("registered", "unknown")
+	}
+
+	reportSection("DNS")
+	switch {
+	case len(res.MXRecords) == 0:
+		reportField("mx", "none")
+	case res.ImplicitMX:
+		reportField("mx", res.MXRecords[0]+" (implicit, from the domain's address records)")
+	default:
+		for i, mx := range res.MXRecords {
+			name := ""
+			if i == 0 {
+				name = "mx"
+			}
+			reportField(name, fmt.Sprintf("%-5d %s", res.MXPreferences[i], mx))
+		}
+	}
+	reportField("spf", orNone(res.SPF))
+	reportField("dmarc", orNone(res.DMARC))
+	reportField("mta-sts", yesNo(res.MTASTS))
+	reportField("tls-rpt", yesNo(res.TLSRP))
+	if p := mxHasPTR(res); p != nil {
+		reportField("reverse dns", yesNo(*p))
+	}
+
+	if res.SMTPServer != "" {
+		reportSection("SMTP")
+		reportField("answered by", fmt.Sprintf("%s:%d", res.SMTPServer, res.SMTPPort))
+		if res.SMTPCode != 0 {
+			reportField("reply", fmt.Sprintf("%d %s", res.SMTPCode, res.SMTPMessage))
+		}
+		reportField("took", res.SMTPDuration.Round(time.Millisecond).String())
+		switch {
+		case res.TLSVersion != "":
+			reportField("tls", fmt.Sprintf("%s, certificate %s, expires %s",
+				res.TLSVersion, validInvalid(res.TLSValid), res.TLSExpiry.Format("2006-01-02")))
+		case res.TLSFailed:
+			reportField("tls", "STARTTLS failed, checked in plaintext")
+		default:
+			reportField("tls", "none")
+		}
+		if res.VRFYCode != 0 {
+			reportField("vrfy", fmt.Sprintf("%d %s", res.VRFYCode, res.VRFYMessage))
+		}
+		if options.CheckCatchAll {
+			reportField("catch-all", yesNo(res.CatchAll))
+		}
+		if p := plusAddressing(res); p != nil {
+			reportField("plus tags", yesNo(*p))
+		}
+	}
+
+	if !options.SyntaxOnly {
+		reportSection("Sending IP")
+		checkBlacklist()
+	}
+	reportVerdict(res, err)
+}
+
+// reportVerdict prints the final section of a report
+func reportVerdict(res verifier.Result, err error) {
+	reportSection("Verdict")
+	reportField("status", string(res.Status))
+	reportField("reason", res.Reason)
+	reportField("score", fmt.Sprintf("%d/100", res.Score))
+	if err != nil {
+		reportField("error", err.Error())
+	}
+}
+
+// reportSection starts a titled section of a report
+func reportSection(title string) {
+	color.Cyan("\n── %s %s", title, strings.Repeat("─", 40-len(title)))
+}
+
+// reportField prints one labelled line of a report, skipping empty values
+func reportField(name, value string) {
+	if value == "" {
+		return
+	}
+	if name != "" {
+		name += ":"
+	}
+	fmt.Printf("   %-15s %s\n", name, value)
+}
+
+// yesNo renders b for a report field
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// validInvalid renders a certificate check for a report field
+func validInvalid(b bool) string {
+	if b {
+		return "valid"
+	}
+	return "invalid"
+}
+
+// orNone renders an optional DNS record for a report field
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
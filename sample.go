@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bufio"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
+// sampleSize, when positive, limits a file run to a random sample of this
+// many emails
+var sampleSize int
+
+// sampleSeed seeds the sample so a run can be repeated; zero uses the clock
+var sampleSeed int64
+
+// readSample reservoir-samples sampleSize emails from scanner in one pass,
+// skipping duplicates unless -no-dedup is set, and returns them in input
+// order
+func readSample(scanner *bufio.Scanner) []string {
+	seed := sampleSeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	type candidate struct {
+		index int
+		email string
+	}
+	var reservoir []candidate
+	seen := make(map[string]bool)
+	for scanner.Scan() {
+		email := strings.TrimSpace(scanner.Text())
+		if email == "" || resumed(email) {
+			continue
+		}
+		if !noDedup {
+			key := dedupKey(email)
+			if seen[key] {
+				stats.duplicates++
+				continue
+			}
+			seen[key] = true
+		}
+		n := stats.population
+		stats.population++
+		if n < sampleSize {
+			reservoir = append(reservoir, candidate{index: n, email: email})
+		} else if j := rng.Intn(n + 1); j < sampleSize {
+			reservoir[j] = candidate{index: n, email: email}
+		}
+	}
+
+	sort.Slice(reservoir, func(i, j int) bool { return reservoir[i].index < reservoir[j].index })
+	sample := make([]string, len(reservoir))
+	for i, c := range reservoir {
+		sample[i] = c.email
+	}
+	return sample
+}
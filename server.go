@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"email-verifier/verifier"
+
+	"github.com/fatih/color"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// maxBatch caps the number of emails accepted in one batch request
+var maxBatch = 1000
+
+// batchRequest is the body of POST /verify/batch
+type batchRequest struct {
+	Emails []string `json:"emails"`
+}
+
+// serve runs the HTTP API on addr until the listener fails
+func serve(addr string) error {
+	// Bound simultaneous verifications across all requests the same way file
+	// mode does, so a burst of traffic cannot open unlimited SMTP connections
+	workers := options.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	slots = make(chan struct{}, workers)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/verify", handleVerify)
+	mux.HandleFunc("/verify/batch", handleBatch)
+	mux.HandleFunc("/verify/stream", handleStream)
+	mux.HandleFunc("/healthz", handleHealthz)
+	mux.Handle("/metrics", promhttp.Handler())
+
+	color.Green("🚀 Listening on %s", addr)
+	return http.ListenAndServe(addr, mux)
+}
+
+// handleVerify answers GET /verify?email=... with the JSON result
+func handleVerify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	email := r.URL.Query().Get("email")
+	if email == "" {
+		writeError(w, http.StatusBadRequest, "missing email parameter")
+		return
+	}
+
+	res, _ := verify(r.Context(), email)
+	writeJSON(w, http.StatusOK, toJSONResult(res))
+}
+
+// handleBatch answers POST /verify/batch with one result per email, in the
+// order the emails were given
+func handleBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var req batchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+		return
+	}
+	if len(req.Emails) > maxBatch {
+		writeError(w, http.StatusRequestEntityTooLarge, "too many emails in batch")
+		return
+	}
+
+	emails := make(chan string)
+	go func() {
+		defer close(emails)
+		for _, email := range req.Emails {
+			emails <- email
+		}
+	}()
+
+	results := make([]jsonResult, 0, len(req.Emails))
+	verifyAll(r.Context(), emails, options.Concurrency, func(res verifier.Result, err error) {
+		results = append(results, toJSONResult(res))
+	})
+	writeJSON(w, http.StatusOK, results)
+}
+
+// handleStream answers POST /verify/stream, whose body holds one email per
+// line, with one NDJSON result per email in input order. Each result is
+// flushed as soon as it is ready, so neither side holds the whole batch.
+func handleStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	// HTTP/1.x servers stop reading the body once the response starts
+	// unless asked not to
+	rc := http.NewResponseController(w)
+	rc.EnableFullDuplex()
+
+	emails := make(chan string)
+	scanner := bufio.NewScanner(r.Body)
+	go func() {
+		defer close(emails)
+		for scanner.Scan() {
+			email := strings.TrimSpace(scanner.Text())
+			if email == "" {
+				continue
+			}
+			select {
+			case emails <- email:
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	verifyAll(r.Context(), emails, options.Concurrency, func(res verifier.Result, err error) {
+		enc.Encode(toJSONResult(res))
+		rc.Flush()
+	})
+}
+
+// handleHealthz reports that the server is up
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// writeJSON sends v as a JSON response with the given status
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError sends a JSON error response
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// exitInterrupted is the exit status of a run cut short by a signal
+const exitInterrupted = 130
+
+// stopping is closed on the first SIGINT or SIGTERM, or when -max-runtime
+// passes. No new emails are dispatched after that, but verifications
+// already running finish.
+var (
+	stopping = make(chan struct{})
+	stopOnce sync.Once
+)
+
+// signalled is set when a signal, rather than the runtime limit, stopped
+// the run
+var signalled atomic.Bool
+
+// runCtx is the context of every verification; a second signal cancels it
+// so verifications in progress give up too
+var runCtx, abort = context.WithCancel(context.Background())
+
+// stopDispatch stops handing out new emails; it is safe to call more than
+// once
+func stopDispatch() {
+	stopOnce.Do(func() { close(stopping) })
+}
+
+// interrupted reports whether the run was asked to stop early
+func interrupted() bool {
+	select {
+	case <-stopping:
+		return true
+	default:
+		return false
+	}
+}
+
+// handleSignals stops dispatching work on the first SIGINT or SIGTERM and
+// cancels verifications in progress on the second, so a long run still
+// flushes its output and prints a partial summary
+func handleSignals() {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		color.Yellow("\n⏹️ Interrupted, finishing verifications in progress (press Ctrl-C again to abort them)")
+		signalled.Store(true)
+		stopDispatch()
+		<-signals
+		color.Yellow("⏹️ Aborting verifications in progress")
+		abort()
+	}()
+}
+
+// limitRuntime stops dispatching new emails once d has passed. Emails
+// already being verified still finish, each within its own -timeout.
+func limitRuntime(d time.Duration) {
+	time.AfterFunc(d, func() {
+		color.Yellow("\n⏰ Max runtime of %s reached, finishing verifications in progress", d)
+		stopDispatch()
+	})
+}
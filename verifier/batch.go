@@ -0,0 +1,57 @@
+package verifier
+
+import (
+	"context"
+	"sync"
+)
+
+// VerifyBatch verifies emails with a verifier built from opts, running
+// opts.Concurrency checks at once, and returns one result per email in
+// input order. Once ctx is done, emails not yet started are returned
+// unverified with the reason "verification cancelled".
+func VerifyBatch(ctx context.Context, emails []string, opts Options) []Result {
+	v := New(opts)
+	defer v.Close()
+	return v.VerifyBatch(ctx, emails)
+}
+
+// VerifyBatch is like the package-level VerifyBatch, using the verifier's
+// options, connection pool and per-domain rate limits
+func (v *Verifier) VerifyBatch(ctx context.Context, emails []string) []Result {
+	results := make([]Result, len(emails))
+	workers := v.opts.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(emails) {
+		workers = len(emails)
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i], _ = v.Verify(ctx, emails[i])
+			}
+		}()
+	}
+
+	next := 0
+dispatch:
+	for ; next < len(emails) && ctx.Err() == nil; next++ {
+		select {
+		case indexes <- next:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(indexes)
+	for ; next < len(emails); next++ {
+		results[next] = Result{Email: emails[next], Status: Unknown, Reason: "verification cancelled"}
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,81 @@
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func BenchmarkVerifySyntaxOnly(b *testing.B) {
+	opts := DefaultOptions()
+	opts.SyntaxOnly = true
+	v := New(opts)
+	ctx := context.Background()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		v.Verify(ctx, "john.doe+news@mail-test.org")
+	}
+}
+
+func BenchmarkGetMXRecordsCached(b *testing.B) {
+	opts := DefaultOptions()
+	opts.Transport.Resolver = &fakeResolver{}
+	v := New(opts)
+	ctx := context.Background()
+	if _, err := v.getMXRecords(ctx, "mail-test.org"); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		v.getMXRecords(ctx, "mail-test.org")
+	}
+}
+
+func BenchmarkLookupDomainCached(b *testing.B) {
+	opts := DefaultOptions()
+	opts.Transport.Resolver = &fakeResolver{}
+	v := New(opts)
+	ctx := context.Background()
+	if _, err := v.lookupDomain(ctx, "mail-test.org"); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		v.lookupDomain(ctx, "mail-test.org")
+	}
+}
+
+// BenchmarkCanonicalize measures the duplicate detection key computed for
+// every input line
+func BenchmarkCanonicalize(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		canonicalize("J.o.h.n+spam@Gmail.com")
+	}
+}
+
+// BenchmarkVerifyBatch verifies 100 addresses across 10 domains against the
+// mock server per iteration, so the pool and domain caches are exercised
+func BenchmarkVerifyBatch(b *testing.B) {
+	for _, workers := range []int{1, 5, 20} {
+		b.Run(fmt.Sprintf("concurrency=%d", workers), func(b *testing.B) {
+			m := startMockSMTP(b, mockScript{rcpt: func(string, int) string { return "250 2.1.5 OK" }})
+			opts := mockOptions(m)
+			opts.Concurrency = workers
+			v := New(opts)
+			defer v.Close()
+
+			emails := make([]string, 100)
+			for i := range emails {
+				emails[i] = fmt.Sprintf("user%d@domain%d.mail-test.org", i, i%10)
+			}
+			ctx := context.Background()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				v.VerifyBatch(ctx, emails)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package verifier
+
+import (
+	"strings"
+)
+
+// canonicalRule describes how a provider folds different spellings of a
+// local part onto the same mailbox
+type canonicalRule struct {
+	stripDots bool   // dots in the local part are ignored
+	stripTags bool   // anything after a '+' is ignored
+	domain    string // alias domains are rewritten to this one
+}
+
+// canonicalRules maps provider domains to their addressing quirks
+var canonicalRules = map[string]canonicalRule{
+	"gmail.com":      {stripDots: true, stripTags: true},
+	"googlemail.com": {stripDots: true, stripTags: true, domain: "gmail.com"},
+	"outlook.com":    {stripTags: true},
+	"hotmail.com":    {stripTags: true},
+	"live.com":       {stripTags: true},
+	"icloud.com":     {stripTags: true},
+	"me.com":         {stripTags: true},
+	"fastmail.com":   {stripTags: true},
+	"protonmail.com": {stripTags: true},
+	"proton.me":      {stripTags: true},
+}
+
+// Canonicalize returns the lowercased form of an email with provider
+// aliasing such as Gmail dots and +tags removed, for spotting duplicate
+// mailboxes before verifying them
+func Canonicalize(email string) string {
+	return canonicalize(email)
+}
+
+// canonicalize returns the lowercased form of an email with provider
+// specific aliasing removed, so that j.o.h.n+spam@gmail.com and
+// john@gmail.com compare equal
+func canonicalize(email string) string {
+	email = strings.ToLower(email)
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return email
+	}
+	local, domain := email[:at], email[at+1:]
+
+	rule, ok := canonicalRules[domain]
+	if !ok {
+		return email
+	}
+	if rule.stripTags {
+		local = stripTag(local)
+	}
+	if rule.stripDots {
+		local = strings.ReplaceAll(local, ".", "")
+	}
+	if rule.domain != "" {
+		domain = rule.domain
+	}
+	return local + "@" + domain
+}
+
+// stripTag removes a "+tag" subaddress from a local part
+func stripTag(local string) string {
+	if i := strings.Index(local, "+"); i >= 0 {
+		return local[:i]
+	}
+	return local
+}
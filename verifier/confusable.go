@@ -0,0 +1,126 @@
+package verifier
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/idna"
+)
+
+// scripts are the writing systems told apart by the confusable check.
+// Letters from other scripts are rare in addresses and are not judged.
+var scripts = []struct {
+	name  string
+	table *unicode.RangeTable
+}{
+	{"Latin", unicode.Latin},
+	{"Cyrillic", unicode.Cyrillic},
+	{"Greek", unicode.Greek},
+	{"Armenian", unicode.Armenian},
+	{"Hebrew", unicode.Hebrew},
+	{"Arabic", unicode.Arabic},
+	{"Han", unicode.Han},
+	{"Hiragana", unicode.Hiragana},
+	{"Katakana", unicode.Katakana},
+	{"Hangul", unicode.Hangul},
+	{"Thai", unicode.Thai},
+	{"Devanagari", unicode.Devanagari},
+}
+
+// latinLookalikes are the Cyrillic, Greek and Armenian letters that render
+// like a Latin letter in common fonts
+var latinLookalikes = map[rune]bool{
+	// Cyrillic
+	'а': true, 'в': true, 'е': true, 'к': true, 'м': true, 'н': true,
+	'о': true, 'р': true, 'с': true, 'т': true, 'у': true, 'х': true,
+	'ѕ': true, 'і': true, 'ј': true, 'ӏ': true, 'ԁ': true, 'ԛ': true,
+	'ԝ': true, 'һ': true, 'ү': true, 'ѵ': true,
+	'А': true, 'В': true, 'Е': true, 'К': true, 'М': true, 'Н': true,
+	'О': true, 'Р': true, 'С': true, 'Т': true, 'Х': true, 'Ѕ': true,
+	'І': true, 'Ј': true,
+	// Greek
+	'α': true, 'ο': true, 'ρ': true, 'ν': true, 'ι': true, 'κ': true,
+	'τ': true, 'υ': true, 'χ': true,
+	'Α': true, 'Β': true, 'Ε': true, 'Ζ': true, 'Η': true, 'Ι': true,
+	'Κ': true, 'Μ': true, 'Ν': true, 'Ο': true, 'Ρ': true, 'Τ': true,
+	'Υ': true, 'Χ': true,
+	// Armenian
+	'օ': true, 'ս': true, 'ց': true, 'ք': true, 'հ': true, 'ո': true,
+}
+
+// cjkScripts may be mixed freely, as Japanese and Korean text does
+var cjkScripts = map[string]bool{"Han": true, "Hiragana": true, "Katakana": true, "Hangul": true}
+
+// confusableCheck reports whether the local part or a domain label of the
+// email mixes scripts, or is written entirely in letters that look Latin,
+// the way lookalike phishing domains are built. The string explains what
+// was found.
+func confusableCheck(email string) (bool, string) {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false, ""
+	}
+	if why := confusableLabel(email[:at]); why != "" {
+		return true, fmt.Sprintf("local part %q %s", email[:at], why)
+	}
+	domain := email[at+1:]
+	if unicodeDomain, err := idna.ToUnicode(domain); err == nil {
+		domain = unicodeDomain
+	}
+	for _, label := range strings.Split(domain, ".") {
+		if why := confusableLabel(label); why != "" {
+			return true, fmt.Sprintf("domain label %q %s", label, why)
+		}
+	}
+	return false, ""
+}
+
+// confusableLabel explains what makes one label suspicious, or returns ""
+func confusableLabel(label string) string {
+	var found []string
+	seen := map[string]bool{}
+	lookalikes := true
+	for _, r := range label {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		name := scriptOf(r)
+		if name != "" && !seen[name] {
+			seen[name] = true
+			found = append(found, name)
+		}
+		if !latinLookalikes[r] {
+			lookalikes = false
+		}
+	}
+
+	switch {
+	case len(found) > 1 && !allCJK(found):
+		return "mixes " + strings.Join(found, " and ") + " letters"
+	case len(found) == 1 && found[0] != "Latin" && lookalikes:
+		return "uses only " + found[0] + " letters that look Latin"
+	}
+	return ""
+}
+
+// scriptOf returns the name of the script r belongs to, or "" when it is
+// not one of scripts
+func scriptOf(r rune) string {
+	for _, s := range scripts {
+		if unicode.Is(s.table, r) {
+			return s.name
+		}
+	}
+	return ""
+}
+
+// allCJK reports whether every script named is a CJK one
+func allCJK(names []string) bool {
+	for _, name := range names {
+		if !cjkScripts[name] {
+			return false
+		}
+	}
+	return true
+}
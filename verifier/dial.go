@@ -0,0 +1,165 @@
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+// dialContext opens a TCP connection to addr with the transport's dialer,
+// or through Options.ProxyURL when set
+func (v *Verifier) dialContext(ctx context.Context, addr string) (net.Conn, error) {
+	if v.opts.Transport.Dial != nil {
+		return v.opts.Transport.Dial(ctx, "tcp", addr)
+	}
+	direct := &net.Dialer{Timeout: v.opts.ConnectTimeout}
+	if v.opts.ProxyURL == "" {
+		return v.dialDirect(ctx, direct, addr)
+	}
+
+	u, err := url.Parse(v.opts.ProxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	dialer, err := proxy.FromURL(u, direct)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported proxy: %w", err)
+	}
+	if cd, ok := dialer.(proxy.ContextDialer); ok {
+		return cd.DialContext(ctx, "tcp", addr)
+	}
+	return dialer.Dial("tcp", addr)
+}
+
+// IPFamily is an IP address family to try first when a mail server has
+// both IPv4 and IPv6 addresses
+type IPFamily int
+
+const (
+	// PreferIPv6 tries AAAA addresses first, falling back to A records
+	PreferIPv6 IPFamily = iota
+	// PreferIPv4 tries A addresses first, falling back to AAAA records
+	PreferIPv4
+)
+
+// fallbackDelay is how long the preferred family gets before the other one
+// is tried in parallel, in the style of Happy Eyeballs (RFC 8305). It is
+// short so that networks with broken IPv6 barely notice.
+const fallbackDelay = 250 * time.Millisecond
+
+// dialDirect resolves the host in addr and connects to its addresses,
+// preferred family first
+func (v *Verifier) dialDirect(ctx context.Context, d *net.Dialer, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || net.ParseIP(host) != nil {
+		return d.DialContext(ctx, "tcp", addr)
+	}
+	ips, err := v.resolver().LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var primary, fallback []string
+	for _, ip := range ips {
+		target := net.JoinHostPort(ip.String(), port)
+		if (ip.IP.To4() == nil) == (v.opts.PreferFamily == PreferIPv6) {
+			primary = append(primary, target)
+		} else {
+			fallback = append(fallback, target)
+		}
+	}
+	if len(primary) == 0 {
+		primary, fallback = fallback, nil
+	}
+	if len(primary) == 0 {
+		return nil, fmt.Errorf("no addresses found for %s", host)
+	}
+	return happyEyeballs(ctx, d, primary, fallback)
+}
+
+// happyEyeballs dials the primary addresses, starting on the fallback ones
+// as well once fallbackDelay passes or the primaries fail, and returns the
+// first connection made
+func happyEyeballs(ctx context.Context, d *net.Dialer, primary, fallback []string) (net.Conn, error) {
+	if len(fallback) == 0 {
+		return dialSerial(ctx, d, primary)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type attempt struct {
+		conn    net.Conn
+		err     error
+		primary bool
+	}
+	results := make(chan attempt, 2)
+	race := func(addrs []string, primary bool) {
+		conn, err := dialSerial(ctx, d, addrs)
+		results <- attempt{conn, err, primary}
+	}
+	go race(primary, true)
+
+	timer := time.NewTimer(fallbackDelay)
+	defer timer.Stop()
+	pending, started := 1, false
+	var primaryErr, fallbackErr error
+	for {
+		select {
+		case <-timer.C:
+			if !started {
+				started = true
+				pending++
+				go race(fallback, false)
+			}
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				// The loser is cancelled, but may have connected already
+				if pending > 0 {
+					go func() {
+						if late := <-results; late.conn != nil {
+							late.conn.Close()
+						}
+					}()
+				}
+				return r.conn, nil
+			}
+			if r.primary {
+				primaryErr = r.err
+			} else {
+				fallbackErr = r.err
+			}
+			if !started {
+				started = true
+				pending++
+				go race(fallback, false)
+				continue
+			}
+			if pending == 0 {
+				if primaryErr != nil {
+					return nil, primaryErr
+				}
+				return nil, fallbackErr
+			}
+		}
+	}
+}
+
+// dialSerial tries each address in turn and returns the first connection
+func dialSerial(ctx context.Context, d *net.Dialer, addrs []string) (net.Conn, error) {
+	var err error
+	for _, addr := range addrs {
+		var conn net.Conn
+		if conn, err = d.DialContext(ctx, "tcp", addr); err == nil {
+			return conn, nil
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, err
+}
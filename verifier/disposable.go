@@ -0,0 +1,33 @@
+package verifier
+
+import (
+	_ "embed"
+	"strings"
+)
+
+//go:embed disposable_domains.txt
+var disposableList string
+
+// disposableDomains is parsed once when the package is loaded
+var disposableDomains = parseList(disposableList)
+
+// isDisposableDomain reports whether the domain belongs to a known
+// disposable or temporary email provider
+func isDisposableDomain(domain string) bool {
+	_, ok := disposableDomains[strings.ToLower(domain)]
+	return ok
+}
+
+// parseList turns a newline separated list into a lookup set,
+// ignoring blank lines and # comments
+func parseList(list string) map[string]struct{} {
+	domains := make(map[string]struct{})
+	for _, line := range strings.Split(list, "\n") {
+		line = strings.ToLower(strings.TrimSpace(line))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		domains[line] = struct{}{}
+	}
+	return domains
+}
@@ -0,0 +1,224 @@
+package verifier
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+// dnsRetryBackoff is the delay before the first MX lookup retry; it
+// doubles on each subsequent attempt
+const dnsRetryBackoff = 250 * time.Millisecond
+
+// mxCacheEntry is a cached MX lookup and the time it stops being valid
+type mxCacheEntry struct {
+	records []*net.MX
+	expires time.Time
+}
+
+// resolver returns the resolver for DNS lookups, honoring the transport and
+// Options.DNSServer
+func (v *Verifier) resolver() Resolver {
+	if v.opts.Transport.Resolver != nil {
+		return v.opts.Transport.Resolver
+	}
+	if v.opts.DNSServer == "" {
+		return net.DefaultResolver
+	}
+	server := v.opts.DNSServer
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, server)
+		},
+	}
+}
+
+// getMXRecords retrieves MX records for the domain, lowest preference first
+func (v *Verifier) getMXRecords(ctx context.Context, domain string) ([]*net.MX, error) {
+	key := strings.ToLower(domain)
+	if v.opts.MXCacheTTL > 0 {
+		entry, ok := v.mxCache.get(key)
+		if ok && time.Now().Before(entry.expires) {
+			Logger.Debug("mx cache hit", "domain", domain)
+			return entry.records, nil
+		}
+	}
+
+	mxRecords, err := v.lookupMX(ctx, domain)
+	if err != nil {
+		Logger.Info("mx lookup failed", "domain", domain, "error", err)
+		return nil, err
+	}
+	sort.SliceStable(mxRecords, func(i, j int) bool {
+		return mxRecords[i].Pref < mxRecords[j].Pref
+	})
+
+	Logger.Info("mx lookup", "domain", domain, "records", len(mxRecords))
+	for _, mx := range mxRecords {
+		Logger.Debug("mx record", "domain", domain, "host", mx.Host, "pref", mx.Pref)
+	}
+
+	if v.opts.MXCacheTTL > 0 {
+		v.mxCache.put(key, mxCacheEntry{records: mxRecords, expires: time.Now().Add(v.opts.MXCacheTTL)})
+	}
+	return mxRecords, nil
+}
+
+// lookupMX queries the domain's MX records, retrying temporary failures
+// with exponential backoff. NXDOMAIN is a definite answer and returns at
+// once.
+func (v *Verifier) lookupMX(ctx context.Context, domain string) ([]*net.MX, error) {
+	backoff := dnsRetryBackoff
+	for attempt := 0; ; attempt++ {
+		mxRecords, err := v.resolver().LookupMX(ctx, domain)
+		if err == nil || isNotFound(err) || attempt >= v.opts.DNSRetries {
+			return mxRecords, err
+		}
+		Logger.Debug("retrying mx lookup", "domain", domain, "attempt", attempt+1, "error", err)
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// getMailHosts returns the hosts that accept mail for the domain. When the
+// domain publishes no MX records, RFC 5321 says its own A/AAAA address is
+// the mail exchanger, in which case implicit is true. A null MX record
+// (RFC 7505) means the domain accepts no mail at all.
+func (v *Verifier) getMailHosts(ctx context.Context, domain string) (hosts []*net.MX, implicit bool, err error) {
+	mxRecords, err := v.getMXRecords(ctx, domain)
+	if err == nil && len(mxRecords) > 0 {
+		if len(mxRecords) == 1 && mxRecords[0].Host == "." {
+			return nil, false, nil
+		}
+		return mxRecords, false, nil
+	}
+	if err != nil && !isNotFound(err) {
+		return nil, false, err
+	}
+
+	Logger.Info("no mx records, trying address records", "domain", domain)
+	addrs, lookupErr := v.resolver().LookupIPAddr(ctx, domain)
+	if lookupErr != nil {
+		return nil, false, lookupErr
+	}
+	if len(addrs) == 0 {
+		return nil, false, err
+	}
+	return []*net.MX{{Host: domain, Pref: 0}}, true, nil
+}
+
+// domainExists reports whether the name has any DNS records at all, which
+// tells a domain without mail servers from one that was never registered.
+// Lookups that fail rather than find nothing count as existing.
+func (v *Verifier) domainExists(ctx context.Context, domain string) bool {
+	resolver := v.resolver()
+	if records, err := resolver.LookupTXT(ctx, domain); len(records) > 0 || (err != nil && !isNotFound(err)) {
+		return true
+	}
+	// NS is not part of Resolver, but net.Resolver and most others have it
+	if ns, ok := resolver.(interface {
+		LookupNS(ctx context.Context, name string) ([]*net.NS, error)
+	}); ok {
+		if records, err := ns.LookupNS(ctx, domain); len(records) > 0 || (err != nil && !isNotFound(err)) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPTR reports whether the mail server's address has forward-confirmed
+// reverse DNS: a PTR name that resolves back to the same address. Servers
+// without it are often misconfigured.
+func (v *Verifier) hasPTR(ctx context.Context, host string) bool {
+	resolver := v.resolver()
+	// PTR is not part of Resolver, but net.Resolver and most others have it
+	ptr, ok := resolver.(interface {
+		LookupAddr(ctx context.Context, addr string) ([]string, error)
+	})
+	if !ok {
+		return false
+	}
+	addrs, err := resolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return false
+	}
+	ip := addrs[0].IP
+	names, err := ptr.LookupAddr(ctx, ip.String())
+	if err != nil {
+		return false
+	}
+	for _, name := range names {
+		forward, err := resolver.LookupIPAddr(ctx, name)
+		if err != nil {
+			continue
+		}
+		for _, addr := range forward {
+			if addr.IP.Equal(ip) {
+				Logger.Debug("mail server reverse dns", "host", host, "ip", ip, "ptr", name)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isNotFound reports whether a DNS error means the name definitely does not
+// exist, as opposed to the lookup failing
+func isNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+// checkSPF looks up the domain's TXT records and returns the SPF policy if
+// one is published
+func (v *Verifier) checkSPF(ctx context.Context, domain string) (bool, string, error) {
+	records, err := v.resolver().LookupTXT(ctx, domain)
+	if err != nil {
+		if isNotFound(err) {
+			return false, "", nil
+		}
+		return false, "", err
+	}
+	for _, record := range records {
+		if strings.HasPrefix(strings.ToLower(record), "v=spf1") {
+			return true, record, nil
+		}
+	}
+	return false, "", nil
+}
+
+// checkDMARC looks up the domain's DMARC record and returns its policy
+// (none, quarantine or reject), or an empty string if none is published
+func (v *Verifier) checkDMARC(ctx context.Context, domain string) (string, error) {
+	records, err := v.resolver().LookupTXT(ctx, "_dmarc."+domain)
+	if err != nil {
+		if isNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	for _, record := range records {
+		if !strings.HasPrefix(strings.ToLower(record), "v=dmarc1") {
+			continue
+		}
+		for _, tag := range strings.Split(record, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(tag), "=")
+			if ok && strings.EqualFold(strings.TrimSpace(key), "p") {
+				return strings.ToLower(strings.TrimSpace(value)), nil
+			}
+		}
+	}
+	return "", nil
+}
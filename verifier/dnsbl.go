@@ -0,0 +1,75 @@
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// dnsblZones are the DNS blocklists most likely to cause mail servers to
+// reject our probes
+var dnsblZones = []string{
+	"zen.spamhaus.org",
+	"bl.spamcop.net",
+	"b.barracudacentral.org",
+	"dnsbl.sorbs.net",
+}
+
+// Blacklisted returns the DNS blocklists that list ip, which make mail
+// servers reject probes from it wholesale. It fails only when no blocklist
+// could be queried at all.
+func (v *Verifier) Blacklisted(ctx context.Context, ip net.IP) ([]string, error) {
+	name, err := reverseName(ip)
+	if err != nil {
+		return nil, err
+	}
+
+	var listed []string
+	var lastErr error
+	answered := 0
+	for _, zone := range dnsblZones {
+		addrs, err := v.resolver().LookupIPAddr(ctx, name+"."+zone)
+		if err != nil {
+			if isNotFound(err) {
+				answered++
+				continue
+			}
+			Logger.Warn("dnsbl lookup failed", "zone", zone, "error", err)
+			lastErr = err
+			continue
+		}
+		answered++
+		for _, addr := range addrs {
+			// 127.255.255.x is an error code, e.g. for queries through
+			// public resolvers, not a listing
+			ip4 := addr.IP.To4()
+			if ip4 != nil && ip4[0] == 127 && !(ip4[1] == 255 && ip4[2] == 255) {
+				listed = append(listed, zone)
+				break
+			}
+		}
+	}
+	if answered == 0 {
+		return nil, lastErr
+	}
+	return listed, nil
+}
+
+// reverseName builds the DNSBL query name for ip: reversed octets for IPv4,
+// reversed nibbles for IPv6
+func reverseName(ip net.IP) (string, error) {
+	if ip4 := ip.To4(); ip4 != nil {
+		return fmt.Sprintf("%d.%d.%d.%d", ip4[3], ip4[2], ip4[1], ip4[0]), nil
+	}
+	ip16 := ip.To16()
+	if ip16 == nil {
+		return "", fmt.Errorf("invalid IP address %v", ip)
+	}
+	const hexDigits = "0123456789abcdef"
+	nibbles := make([]string, 0, 32)
+	for i := len(ip16) - 1; i >= 0; i-- {
+		nibbles = append(nibbles, string(hexDigits[ip16[i]&0xf]), string(hexDigits[ip16[i]>>4]))
+	}
+	return strings.Join(nibbles, "."), nil
+}
@@ -0,0 +1,295 @@
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// DomainResult holds the outcome of the domain-level checks, for when
+// there is no particular mailbox to check
+type DomainResult struct {
+	Domain       string
+	ASCIIDomain  string
+	Disposable   bool
+	FreeProvider bool
+	Reserved     bool
+	MXRecords    []string
+	ImplicitMX   bool
+	SPF          string
+	DMARC        string
+	MTASTS       bool
+	TLSRP        bool
+	MXHasPTR     bool
+	SMTPServer   string
+	SMTPPort     int
+	TLSVersion   string
+	Reachable    bool // a mail server completed the SMTP handshake
+	CatchAll     bool
+	Status       Status
+	Reason       string
+}
+
+// VerifyDomain runs the checks that need no mailbox on a domain using a
+// verifier built from opts: MX and address records, SPF, DMARC, MTA-STS
+// and TLS-RPT, and whether a mail server answers. With CheckCatchAll set
+// it also learns whether the domain accepts every recipient.
+func VerifyDomain(domain string, opts Options) DomainResult {
+	v := New(opts)
+	defer v.Close()
+	res, _ := v.VerifyDomain(context.Background(), domain)
+	return res
+}
+
+// VerifyDomain is like the package-level VerifyDomain, using the
+// verifier's options and caches. The returned error is non-nil when a
+// network step could not be completed.
+func (v *Verifier) VerifyDomain(ctx context.Context, domain string) (DomainResult, error) {
+	if v.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, v.opts.Timeout)
+		defer cancel()
+	}
+	res := DomainResult{Domain: domain}
+	ascii, err := toASCIIDomain(cleanInput(domain))
+	if err != nil || !strings.Contains(ascii, ".") {
+		res.Status = InvalidSyntax
+		res.Reason = "invalid domain name"
+		return res, nil
+	}
+	res.ASCIIDomain = ascii
+	res.Disposable = isDisposableDomain(ascii)
+	res.FreeProvider = isFreeProvider(ascii)
+	if isReservedDomain(ascii) {
+		res.Reserved = true
+		res.Status = Undeliverable
+		res.Reason = "reserved domain, not deliverable"
+		return res, nil
+	}
+	if v.opts.SyntaxOnly {
+		res.Status = Skipped
+		res.Reason = "syntax only, network checks skipped"
+		return res, nil
+	}
+
+	info, err := v.lookupDomain(ctx, ascii)
+	if err != nil {
+		res.Status = Unknown
+		res.Reason = fmt.Sprintf("DNS lookup failed: %v", err)
+		return res, err
+	}
+	if len(info.hosts) == 0 {
+		res.Status, res.Reason = info.noMail()
+		return res, nil
+	}
+	res.ImplicitMX = info.implicit
+	for _, mx := range info.hosts {
+		res.MXRecords = append(res.MXRecords, mx.Host)
+	}
+	res.SPF = info.spf
+	res.DMARC = info.dmarc
+	res.MTASTS = info.mtasts
+	res.TLSRP = info.tlsrpt
+	res.MXHasPTR = info.ptr
+
+	if v.opts.SkipSMTP {
+		res.Status = Skipped
+		res.Reason = "SMTP check skipped"
+		return res, nil
+	}
+	err = v.probeDomain(ctx, ascii, info.hosts, &res)
+	return res, err
+}
+
+// probeDomain connects to the domain's mail servers like checkSMTP, but
+// only sends a RCPT TO for the catch-all probe
+func (v *Verifier) probeDomain(ctx context.Context, domain string, hosts []*net.MX, res *DomainResult) error {
+	res.Status = Unknown
+	if err := v.waitForDomain(ctx, domain); err != nil {
+		res.Reason = "gave up waiting for the domain rate limit"
+		return err
+	}
+
+	client := v.takeSession(ctx, domain)
+	var err error
+	for _, mx := range v.mxToTry(hosts) {
+		if client != nil || ctx.Err() != nil {
+			break
+		}
+		client, _, err = v.connectMX(ctx, mx.Host)
+	}
+	if client == nil {
+		if err == nil {
+			err = ctx.Err()
+		}
+		res.Reason = connectReason(err)
+		return err
+	}
+	res.Reachable = true
+	res.SMTPServer = client.host
+	res.SMTPPort = client.port
+	if state, ok := client.TLSConnectionState(); ok {
+		res.TLSVersion = tlsVersionName(state.Version)
+	}
+	broken := false
+	defer func() { v.releaseSession(domain, client, !broken) }()
+
+	res.Status = Deliverable
+	res.Reason = "mail server reachable"
+	if !v.opts.CheckCatchAll {
+		return nil
+	}
+	if catchAll, ok := v.knownCatchAll(domain); ok {
+		res.CatchAll = catchAll
+	} else {
+		if err = smtpCommand(client, "MAIL FROM:<%s>", client.from); err != nil {
+			broken = smtpCode(err) == 0
+			res.Reason = replyReason("sender rejected", "MAIL FROM failed", err)
+			return nil
+		}
+		err = smtpCommand(client, "RCPT TO:<%s>", randomLocalPart()+"@"+domain)
+		client.rcpts++
+		broken = err != nil && smtpCode(err) == 0
+		res.CatchAll = err == nil
+		if err == nil || smtpCode(err) >= 500 {
+			v.rememberCatchAll(domain, res.CatchAll)
+		}
+	}
+	if res.CatchAll {
+		res.Status = RiskyCatchAll
+		res.Reason = "mail server reachable, catch-all domain"
+	}
+	return nil
+}
+
+// noMail is the status and reason of a domain without mail hosts
+func (info *domainInfo) noMail() (Status, string) {
+	switch {
+	case info.nullMX:
+		return NoMX, "domain accepts no mail (null MX)"
+	case info.exists:
+		return NoMX, "domain exists but has no mail server"
+	default:
+		return NoDomain, "domain does not exist (NXDOMAIN)"
+	}
+}
+
+// domainInfo is everything learned about a domain that does not depend on
+// the mailbox, shared by every email at the domain for Options.MXCacheTTL
+type domainInfo struct {
+	hosts    []*net.MX
+	implicit bool
+	nullMX   bool // the domain publishes a null MX, so accepts no mail
+	exists   bool // only looked up when the domain has no mail hosts
+	spf      string
+	dmarc    string
+	mtasts   bool
+	tlsrpt   bool
+	ptr      bool
+
+	// catchAll is set once a probe got a definite answer
+	catchAllKnown bool
+	catchAll      bool
+
+	expires time.Time
+}
+
+// lookupDomain returns the mail hosts and DNS policies of the domain,
+// reusing what an earlier email at the domain found. The error is non-nil
+// only when the mail host lookup failed rather than found nothing.
+func (v *Verifier) lookupDomain(ctx context.Context, domain string) (*domainInfo, error) {
+	key := strings.ToLower(domain)
+	if cached := v.cachedDomain(key); cached != nil {
+		Logger.Debug("domain cache hit", "domain", domain)
+		return cached, nil
+	}
+
+	if v.opts.DNSTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, v.opts.DNSTimeout)
+		defer cancel()
+	}
+
+	info := &domainInfo{}
+	if v.opts.Server != "" {
+		// A fixed server stands in for the MX hosts
+		host, _ := v.server()
+		info.hosts = []*net.MX{{Host: host}}
+	} else {
+		hosts, implicit, err := v.getMailHosts(ctx, domain)
+		if err != nil && !isNotFound(err) {
+			return nil, atStage("DNS lookup", err)
+		}
+		info.hosts, info.implicit = hosts, implicit
+		if len(hosts) == 0 {
+			info.nullMX = err == nil
+			info.exists = info.nullMX || v.domainExists(ctx, domain)
+			v.storeDomain(key, info)
+			return info, nil
+		}
+	}
+
+	// SPF, DMARC, MTA-STS and TLS-RPT are informational only, so failed
+	// lookups do not stop verification, but they are not cached either
+	complete := true
+	var err error
+	if _, info.spf, err = v.checkSPF(ctx, domain); err != nil {
+		complete = false
+	}
+	if info.dmarc, err = v.checkDMARC(ctx, domain); err != nil {
+		complete = false
+	}
+	info.mtasts = v.checkMTASTS(ctx, domain)
+	info.tlsrpt = v.checkTLSRPT(ctx, domain)
+	if v.opts.CheckPTR {
+		info.ptr = v.hasPTR(ctx, info.hosts[0].Host)
+	}
+	if complete {
+		v.storeDomain(key, info)
+	}
+	return info, nil
+}
+
+// cachedDomain returns the unexpired cache entry for key, or nil
+func (v *Verifier) cachedDomain(key string) *domainInfo {
+	info, ok := v.domains.get(key)
+	if !ok || time.Now().After(info.expires) {
+		return nil
+	}
+	return info
+}
+
+// storeDomain caches info under key until Options.MXCacheTTL passes
+func (v *Verifier) storeDomain(key string, info *domainInfo) {
+	if v.opts.MXCacheTTL <= 0 {
+		return
+	}
+	info.expires = time.Now().Add(v.opts.MXCacheTTL)
+	v.domains.put(key, info)
+}
+
+// knownCatchAll returns the domain's catch-all status when an earlier probe
+// settled it
+func (v *Verifier) knownCatchAll(domain string) (catchAll, ok bool) {
+	info := v.cachedDomain(strings.ToLower(domain))
+	if info == nil {
+		return false, false
+	}
+	v.domainsMu.Lock()
+	defer v.domainsMu.Unlock()
+	return info.catchAll, info.catchAllKnown
+}
+
+// rememberCatchAll records the outcome of a catch-all probe for the
+// domain's later emails
+func (v *Verifier) rememberCatchAll(domain string, catchAll bool) {
+	info := v.cachedDomain(strings.ToLower(domain))
+	if info == nil {
+		return
+	}
+	v.domainsMu.Lock()
+	defer v.domainsMu.Unlock()
+	info.catchAll, info.catchAllKnown = catchAll, true
+}
@@ -0,0 +1,102 @@
+package verifier
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAdvertises(t *testing.T) {
+	tests := []struct {
+		reply []string
+		want  bool
+	}{
+		{[]string{"250-mock.test", "250-SIZE 1000", "250 STARTTLS"}, true},
+		{[]string{"250-mock.test", "250 starttls"}, true},
+		{[]string{"250 mock.test Hello STARTTLS"}, true},
+		{[]string{"250-mock.test", "250-PIPELINING StartTLS", "250 SIZE"}, true},
+		{[]string{"250-mock.test", "250 STARTTLSX"}, false},
+		{[]string{"220 mock.test STARTTLS"}, false},
+		{[]string{"250-mock.test", "250 SIZE 1000"}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := advertises(tt.reply, "STARTTLS"); got != tt.want {
+			t.Errorf("advertises(%q) = %v, want %v", tt.reply, got, tt.want)
+		}
+	}
+}
+
+// awkwardGreeting is a multi-line 220 banner with free text on every line
+var awkwardGreeting = []string{
+	"220-mock.test ESMTP",
+	"220-This system is for authorized use only.",
+	"220 All activity is logged.",
+}
+
+func TestDialSMTPAwkwardEHLO(t *testing.T) {
+	tests := []struct {
+		name    string
+		ehlo    []string
+		wantErr error // errSTARTTLS once STARTTLS is found and tried, else errNoSTARTTLS
+	}{
+		{
+			// STARTTLS is found, so it is attempted and the 454 reply fails
+			// the handshake rather than the check for STARTTLS support
+			name:    "lowercase and wrapped",
+			ehlo:    []string{"250-mock.test Hello", "250-8BITMIME", "250-pipelining starttls", "250 SIZE 10240000"},
+			wantErr: errSTARTTLS,
+		},
+		{
+			name:    "on the first line",
+			ehlo:    []string{"250-mock.test STARTTLS", "250 8BITMIME"},
+			wantErr: errSTARTTLS,
+		},
+		{
+			name:    "not offered",
+			ehlo:    []string{"250-mock.test Hello", "250-8BITMIME", "250 SIZE 10240000"},
+			wantErr: errNoSTARTTLS,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := startMockSMTP(t, mockScript{
+				greeting: awkwardGreeting,
+				ehlo:     tt.ehlo,
+				starttls: "454 4.7.0 TLS not available due to temporary reason",
+				rcpt:     acceptOnly("alice@mail-test.org"),
+			})
+			v := New(mockOptions(m))
+			defer v.Close()
+
+			_, err := v.dialSMTP(context.Background(), "mx.mail-test.org", 25, TLSRequired)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("dialSMTP error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDialSMTPFallsBackToHELO(t *testing.T) {
+	m := startMockSMTP(t, mockScript{
+		greeting: awkwardGreeting,
+		ehlo:     []string{"502 5.5.2 EHLO not supported"},
+		rcpt:     acceptOnly("alice@mail-test.org"),
+	})
+	v := New(mockOptions(m))
+	defer v.Close()
+
+	res, err := v.Verify(context.Background(), "alice@mail-test.org")
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if res.Status != Deliverable {
+		t.Errorf("Status = %q, want %q (reason %q)", res.Status, Deliverable, res.Reason)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.helos) != 2 || !strings.HasPrefix(m.helos[0], "EHLO") || !strings.HasPrefix(m.helos[1], "HELO") {
+		t.Errorf("greetings sent = %q, want EHLO then HELO", m.helos)
+	}
+}
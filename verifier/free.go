@@ -0,0 +1,19 @@
+package verifier
+
+import (
+	_ "embed"
+	"strings"
+)
+
+//go:embed free_providers.txt
+var freeProviderList string
+
+// freeProviders is parsed once when the package is loaded
+var freeProviders = parseList(freeProviderList)
+
+// isFreeProvider reports whether the domain belongs to a consumer email
+// provider rather than an organization
+func isFreeProvider(domain string) bool {
+	_, ok := freeProviders[strings.ToLower(domain)]
+	return ok
+}
@@ -0,0 +1,31 @@
+package verifier
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// gravatarClient bounds how long a slow Gravatar lookup can take
+var gravatarClient = &http.Client{Timeout: 5 * time.Second}
+
+// hasGravatar reports whether a Gravatar image exists for the email. Any
+// failure to reach Gravatar is treated as no image.
+func hasGravatar(ctx context.Context, email string) bool {
+	sum := md5.Sum([]byte(strings.ToLower(strings.TrimSpace(email))))
+	url := "https://www.gravatar.com/avatar/" + hex.EncodeToString(sum[:]) + "?d=404"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := gravatarClient.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
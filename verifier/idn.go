@@ -0,0 +1,35 @@
+package verifier
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/text/unicode/norm"
+)
+
+// toASCIIDomain converts an internationalized domain such as münchen.de to
+// the punycode form used by DNS and SMTP
+func toASCIIDomain(domain string) (string, error) {
+	return idna.Lookup.ToASCII(domain)
+}
+
+// normalizeLocalPart puts a UTF-8 local part into Unicode NFC form, as
+// RFC 6531 requires for internationalized addresses
+func normalizeLocalPart(local string) string {
+	return norm.NFC.String(local)
+}
+
+// cleanInput strips what copying from spreadsheets and web forms leaves
+// behind: surrounding whitespace, non-breaking spaces included, and
+// zero-width and other invisible formatting characters anywhere. The
+// result is in Unicode NFC form.
+func cleanInput(email string) string {
+	email = strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.Cf, r) {
+			return -1
+		}
+		return r
+	}, email)
+	return norm.NFC.String(strings.TrimFunc(email, unicode.IsSpace))
+}
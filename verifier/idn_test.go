@@ -0,0 +1,58 @@
+package verifier
+
+import (
+	"context"
+	"testing"
+)
+
+func TestToASCIIDomain(t *testing.T) {
+	tests := []struct {
+		domain string
+		want   string
+	}{
+		{"münchen.de", "xn--mnchen-3ya.de"},
+		{"MÜNCHEN.de", "xn--mnchen-3ya.de"},
+		{"bücher.de", "xn--bcher-kva.de"},
+		{"straße.de", "xn--strae-oqa.de"},
+		{"例え.jp", "xn--r8jz45g.jp"},
+		{"пример.рф", "xn--e1afmkfd.xn--p1ai"},
+		{"example.com", "example.com"},
+		{"xn--mnchen-3ya.de", "xn--mnchen-3ya.de"},
+	}
+	for _, tt := range tests {
+		got, err := toASCIIDomain(tt.domain)
+		if err != nil || got != tt.want {
+			t.Errorf("toASCIIDomain(%q) = %q, %v, want %q", tt.domain, got, err, tt.want)
+		}
+	}
+}
+
+func TestNormalizeLocalPart(t *testing.T) {
+	// "e" followed by a combining acute accent composes to "é"
+	if got := normalizeLocalPart("jose\u0301"); got != "jos\u00e9" {
+		t.Errorf("normalizeLocalPart(decomposed) = %q, want %q", got, "jos\u00e9")
+	}
+}
+
+func TestVerifyIDN(t *testing.T) {
+	m := startMockSMTP(t, mockScript{rcpt: acceptOnly("hans@xn--mnchen-3ya.de")})
+	resolver := &fakeResolver{}
+	opts := mockOptions(m)
+	opts.Transport.Resolver = resolver
+	v := New(opts)
+	defer v.Close()
+
+	res, err := v.Verify(context.Background(), "hans@münchen.de")
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if res.Domain != "münchen.de" || res.ASCIIDomain != "xn--mnchen-3ya.de" {
+		t.Errorf("Domain, ASCIIDomain = %q, %q, want the original and its punycode", res.Domain, res.ASCIIDomain)
+	}
+	if len(resolver.lookups) == 0 || resolver.lookups[0] != "xn--mnchen-3ya.de" {
+		t.Errorf("MX lookups = %q, want the punycode domain", resolver.lookups)
+	}
+	if res.Status != Deliverable {
+		t.Errorf("Status = %q, want %q (reason %q)", res.Status, Deliverable, res.Reason)
+	}
+}
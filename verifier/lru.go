@@ -0,0 +1,58 @@
+package verifier
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lru is a cache holding at most size entries, evicting the least recently
+// used one to make room. It is safe for concurrent use; a size of zero or
+// less leaves it unbounded.
+type lru[K comparable, V any] struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List // of *lruEntry, most recently used first
+	items map[K]*list.Element
+}
+
+// lruEntry is one key and value in an lru
+type lruEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// newLRU returns an empty cache holding at most size entries
+func newLRU[K comparable, V any](size int) *lru[K, V] {
+	return &lru[K, V]{size: size, order: list.New(), items: make(map[K]*list.Element)}
+}
+
+// get returns the value cached for key, marking it recently used
+func (c *lru[K, V]) get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.items[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*lruEntry[K, V]).value, true
+}
+
+// put caches value for key, evicting the least recently used entry when
+// the cache is full
+func (c *lru[K, V]) put(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[key]; ok {
+		elem.Value.(*lruEntry[K, V]).value = value
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.items[key] = c.order.PushFront(&lruEntry[K, V]{key: key, value: value})
+	if c.size > 0 && c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry[K, V]).key)
+	}
+}
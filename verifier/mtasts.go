@@ -0,0 +1,64 @@
+package verifier
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// mtaSTSClient fetches MTA-STS policy files
+var mtaSTSClient = &http.Client{Timeout: 10 * time.Second}
+
+// checkMTASTS reports whether the domain publishes an MTA-STS policy
+// (RFC 8461): a v=STSv1 TXT record at _mta-sts and a policy file served
+// over HTTPS from the mta-sts host
+func (v *Verifier) checkMTASTS(ctx context.Context, domain string) bool {
+	records, err := v.resolver().LookupTXT(ctx, "_mta-sts."+domain)
+	if err != nil || !hasTXTPrefix(records, "v=STSv1") {
+		return false
+	}
+
+	url := "https://mta-sts." + domain + "/.well-known/mta-sts.txt"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := mtaSTSClient.Do(req)
+	if err != nil {
+		Logger.Info("mta-sts policy fetch failed", "domain", domain, "error", err)
+		return false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+
+	scanner := bufio.NewScanner(io.LimitReader(resp.Body, 64*1024))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if ok && strings.TrimSpace(key) == "version" && strings.TrimSpace(value) == "STSv1" {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTLSRPT reports whether the domain asks for SMTP TLS failure reports
+// (RFC 8460) with a v=TLSRPTv1 TXT record at _smtp._tls
+func (v *Verifier) checkTLSRPT(ctx context.Context, domain string) bool {
+	records, err := v.resolver().LookupTXT(ctx, "_smtp._tls."+domain)
+	return err == nil && hasTXTPrefix(records, "v=TLSRPTv1")
+}
+
+// hasTXTPrefix reports whether any record starts with prefix, ignoring case
+func hasTXTPrefix(records []string, prefix string) bool {
+	for _, record := range records {
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(record)), strings.ToLower(prefix)) {
+			return true
+		}
+	}
+	return false
+}
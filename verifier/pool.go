@@ -0,0 +1,121 @@
+package verifier
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// maxIdle is how long an unused session is kept before it is closed; most
+// servers drop idle clients after a few minutes
+const maxIdle = 30 * time.Second
+
+// takeSession returns an idle session with the domain's mail server, or nil
+// if there is none still alive. Each candidate is checked with NOOP before
+// it is handed out; any reply, even an error code, shows it is alive.
+func (v *Verifier) takeSession(ctx context.Context, domain string) *session {
+	key := strings.ToLower(domain)
+	for {
+		v.idleMu.Lock()
+		sessions := v.idle[key]
+		if len(sessions) == 0 {
+			v.idleMu.Unlock()
+			return nil
+		}
+		s := sessions[len(sessions)-1]
+		if len(sessions) == 1 {
+			delete(v.idle, key)
+		} else {
+			v.idle[key] = sessions[:len(sessions)-1]
+		}
+		v.idleMu.Unlock()
+
+		if time.Since(s.idle) > maxIdle {
+			endSession(s, true)
+			continue
+		}
+		s.ctx = ctx
+		s.arm()
+		if err := s.Noop(); err != nil && smtpCode(err) == 0 {
+			Logger.Debug("dropping stale smtp session", "host", s.host, "error", err)
+			s.Close()
+			continue
+		}
+		Logger.Debug("reusing smtp session", "host", s.host, "rcpts", s.rcpts)
+		return s
+	}
+}
+
+// releaseSession keeps a healthy session for the next address at the
+// domain while it has RCPT commands to spare, resetting the transaction
+// with RSET first. Any other session is ended; see endSession.
+func (v *Verifier) releaseSession(domain string, s *session, graceful bool) {
+	need := 1
+	if v.opts.CheckCatchAll {
+		need++
+	}
+	if v.opts.CheckPlusAddressing {
+		need++
+	}
+	if !graceful || s.rcpts+need > v.opts.RcptPerConn {
+		endSession(s, graceful)
+		return
+	}
+	s.arm()
+	if err := s.Reset(); err != nil {
+		endSession(s, smtpCode(err) != 0)
+		return
+	}
+	s.idle = time.Now()
+
+	key := strings.ToLower(domain)
+	v.idleMu.Lock()
+	v.idle[key] = append(v.idle[key], s)
+	expired := v.takeExpired()
+	v.idleMu.Unlock()
+	for _, s := range expired {
+		s.ctx = context.Background()
+		s.arm()
+		endSession(s, true)
+	}
+}
+
+// takeExpired removes the sessions idle for longer than maxIdle from the
+// pool and returns them, so domains that are never checked again do not
+// keep sessions forever. The caller holds idleMu.
+func (v *Verifier) takeExpired() []*session {
+	var expired []*session
+	for key, sessions := range v.idle {
+		kept := sessions[:0]
+		for _, s := range sessions {
+			if time.Since(s.idle) > maxIdle {
+				expired = append(expired, s)
+			} else {
+				kept = append(kept, s)
+			}
+		}
+		if len(kept) == 0 {
+			delete(v.idle, key)
+		} else {
+			v.idle[key] = kept
+		}
+	}
+	return expired
+}
+
+// Close ends every idle SMTP session kept for reuse. The Verifier remains
+// usable afterwards.
+func (v *Verifier) Close() {
+	v.idleMu.Lock()
+	idle := v.idle
+	v.idle = make(map[string][]*session)
+	v.idleMu.Unlock()
+
+	for _, sessions := range idle {
+		for _, s := range sessions {
+			s.ctx = context.Background()
+			s.arm()
+			endSession(s, true)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package verifier
+
+import "strings"
+
+// acceptAllProvider is a mail provider whose servers accept every RCPT TO,
+// so a successful SMTP check says nothing about the mailbox. They reject
+// unknown mailboxes only after the message has been sent.
+type acceptAllProvider struct {
+	name    string
+	domains []string // the provider's own address domains
+	mx      []string // MX host suffixes, which also catch hosted domains
+}
+
+// acceptAllProviders lists the known accept-all providers; add entries here
+// as more providers change behavior
+var acceptAllProviders = []acceptAllProvider{
+	{
+		name: "Yahoo",
+		domains: []string{
+			"yahoo.com", "yahoo.co.uk", "yahoo.fr", "yahoo.de", "yahoo.it",
+			"yahoo.es", "yahoo.ca", "yahoo.com.au", "yahoo.co.in", "yahoo.co.jp",
+			"ymail.com", "rocketmail.com",
+		},
+		mx: []string{".yahoodns.net"},
+	},
+	{
+		// AOL mail runs on Yahoo's servers, so its MX hosts match above
+		name:    "AOL",
+		domains: []string{"aol.com", "aim.com", "love.com", "games.com", "wow.com"},
+	},
+}
+
+// acceptAllProviderName returns the name of the accept-all provider that
+// handles mail for the domain, judged by the domain itself or its MX hosts
+func acceptAllProviderName(domain string, mxHosts []string) (string, bool) {
+	domain = strings.ToLower(domain)
+	for _, p := range acceptAllProviders {
+		for _, d := range p.domains {
+			if domain == d {
+				return p.name, true
+			}
+		}
+	}
+	for _, host := range mxHosts {
+		host = strings.TrimSuffix(strings.ToLower(host), ".")
+		for _, p := range acceptAllProviders {
+			for _, suffix := range p.mx {
+				if strings.HasSuffix(host, suffix) {
+					return p.name, true
+				}
+			}
+		}
+	}
+	return "", false
+}
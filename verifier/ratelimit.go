@@ -0,0 +1,27 @@
+package verifier
+
+import (
+	"context"
+	"strings"
+
+	"golang.org/x/time/rate"
+)
+
+// waitForDomain blocks until another SMTP probe to the domain is allowed
+// by Options.DomainRate or ctx is done
+func (v *Verifier) waitForDomain(ctx context.Context, domain string) error {
+	if v.opts.DomainRate <= 0 {
+		return nil
+	}
+	domain = strings.ToLower(domain)
+
+	v.limitersMu.Lock()
+	limiter, ok := v.limiters.get(domain)
+	if !ok {
+		limiter = rate.NewLimiter(rate.Limit(v.opts.DomainRate), 1)
+		v.limiters.put(domain, limiter)
+	}
+	v.limitersMu.Unlock()
+
+	return limiter.Wait(ctx)
+}
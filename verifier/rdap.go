@@ -0,0 +1,78 @@
+package verifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// rdapURL is the RDAP bootstrap service, which redirects each query to the
+// registry responsible for the domain's TLD
+const rdapURL = "https://rdap.org/domain/"
+
+// rdapClient bounds how long a slow registry can hold up verification
+var rdapClient = &http.Client{Timeout: 10 * time.Second}
+
+// rdapDomain is the part of an RDAP domain response we use
+type rdapDomain struct {
+	Events []struct {
+		Action string    `json:"eventAction"`
+		Date   time.Time `json:"eventDate"`
+	} `json:"events"`
+}
+
+// domainCreated returns when the registered domain behind domain was
+// created, according to RDAP. The zero time means unknown: the TLD has no
+// RDAP service, the registry did not answer, or it does not publish the
+// date. Answers are remembered for the life of the Verifier.
+func (v *Verifier) domainCreated(ctx context.Context, domain string) time.Time {
+	registered, err := publicsuffix.EffectiveTLDPlusOne(strings.ToLower(strings.TrimSuffix(domain, ".")))
+	if err != nil {
+		return time.Time{}
+	}
+
+	if created, ok := v.rdapCache.get(registered); ok {
+		return created
+	}
+
+	created := lookupRegistration(ctx, registered)
+	if ctx.Err() == nil {
+		v.rdapCache.put(registered, created)
+	}
+	return created
+}
+
+// lookupRegistration queries RDAP for a domain's registration date
+func lookupRegistration(ctx context.Context, domain string) time.Time {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rdapURL+domain, nil)
+	if err != nil {
+		return time.Time{}
+	}
+	req.Header.Set("Accept", "application/rdap+json")
+	resp, err := rdapClient.Do(req)
+	if err != nil {
+		Logger.Info("rdap lookup failed", "domain", domain, "error", err)
+		return time.Time{}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		Logger.Info("rdap lookup failed", "domain", domain, "status", resp.StatusCode)
+		return time.Time{}
+	}
+
+	var info rdapDomain
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		Logger.Info("rdap response unreadable", "domain", domain, "error", err)
+		return time.Time{}
+	}
+	for _, event := range info.Events {
+		if event.Action == "registration" {
+			return event.Date
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,35 @@
+package verifier
+
+import "strings"
+
+// reservedDomains are the second-level domains RFC 2606 sets aside for
+// documentation; they and their subdomains never receive real mail
+var reservedDomains = []string{"example.com", "example.net", "example.org"}
+
+// reservedTLDs are the top-level domains RFC 2606 reserves for testing,
+// examples and invalid names
+var reservedTLDs = []string{"test", "example", "invalid", "localhost"}
+
+// isReservedDomain reports whether the domain falls under one of the names
+// reserved by RFC 2606
+func isReservedDomain(domain string) bool {
+	domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+	for _, reserved := range reservedDomains {
+		if domain == reserved || strings.HasSuffix(domain, "."+reserved) {
+			return true
+		}
+	}
+	tld := domain[strings.LastIndex(domain, ".")+1:]
+	for _, reserved := range reservedTLDs {
+		if tld == reserved {
+			return true
+		}
+	}
+	return false
+}
+
+// IsReservedDomain reports whether the domain is reserved by RFC 2606, so
+// never receives mail; callers can use it to vet their own sender domain
+func IsReservedDomain(domain string) bool {
+	return isReservedDomain(domain)
+}
@@ -0,0 +1,54 @@
+package verifier
+
+import (
+	"os"
+	"strings"
+)
+
+// roleLocalParts holds local parts that belong to shared role accounts
+// rather than a person
+var roleLocalParts = parseList(`
+abuse
+admin
+administrator
+billing
+contact
+help
+hello
+hostmaster
+info
+jobs
+marketing
+no-reply
+noreply
+office
+postmaster
+sales
+security
+support
+team
+webmaster
+`)
+
+// LoadRolesFile replaces the built-in role account list with the local
+// parts listed in a file, one per line
+func LoadRolesFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	roleLocalParts = parseList(string(data))
+	return nil
+}
+
+// isRoleBased reports whether the email's local part is a generic role
+// account such as info@ or support@, ignoring any +tag so that
+// support+ticket123@ is caught too
+func isRoleBased(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	_, ok := roleLocalParts[strings.ToLower(stripTag(email[:at]))]
+	return ok
+}
@@ -0,0 +1,59 @@
+package verifier
+
+import "testing"
+
+func TestIsRoleBased(t *testing.T) {
+	tests := []struct {
+		email string
+		want  bool
+	}{
+		{"info@example.com", true},
+		{"info+foo@example.com", true},
+		{"Support+ticket123@example.com", true},
+		{"admin+@example.com", true},
+		{"admin@example.com", true},
+		{"admin.test@example.com", false}, // only +tags are stripped, not dots
+		{"administrator.jane@example.com", false},
+		{"jane+info@example.com", false},
+		{"jane@example.com", false},
+		{"not-an-email", false},
+	}
+	for _, tt := range tests {
+		if got := isRoleBased(tt.email); got != tt.want {
+			t.Errorf("isRoleBased(%q) = %v, want %v", tt.email, got, tt.want)
+		}
+	}
+}
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{"J.o.h.n+spam@Gmail.com", "john@gmail.com"},
+		{"john+news@googlemail.com", "john@gmail.com"},
+		{"info+foo@outlook.com", "info@outlook.com"},
+		{"admin.test@outlook.com", "admin.test@outlook.com"},
+		{"info+foo@example.com", "info+foo@example.com"},
+	}
+	for _, tt := range tests {
+		if got := canonicalize(tt.email); got != tt.want {
+			t.Errorf("canonicalize(%q) = %q, want %q", tt.email, got, tt.want)
+		}
+	}
+}
+
+func TestStripTag(t *testing.T) {
+	tests := map[string]string{
+		"info+foo":     "info",
+		"info+foo+bar": "info",
+		"info":         "info",
+		"+foo":         "",
+		"admin.test":   "admin.test",
+	}
+	for local, want := range tests {
+		if got := stripTag(local); got != want {
+			t.Errorf("stripTag(%q) = %q, want %q", local, got, want)
+		}
+	}
+}
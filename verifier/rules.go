@@ -0,0 +1,56 @@
+package verifier
+
+import (
+	"fmt"
+	"sync"
+)
+
+// RuleFunc is a custom check run on every well-formed address after the
+// built-in checks. It may annotate r; a non-nil error fails the address.
+type RuleFunc func(email string, r *Result) error
+
+// rule is a registered custom check
+type rule struct {
+	name string
+	fn   RuleFunc
+}
+
+var (
+	rulesMu sync.RWMutex
+	rules   []rule
+)
+
+// RegisterRule adds a custom check that every Verifier runs after its
+// built-in checks, in registration order. Registering a name again
+// replaces the earlier rule in place.
+func RegisterRule(name string, fn func(email string, r *Result) error) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	for i := range rules {
+		if rules[i].name == name {
+			rules[i].fn = fn
+			return
+		}
+	}
+	rules = append(rules, rule{name: name, fn: fn})
+}
+
+// applyRules runs the registered rules on res, stopping at the first that
+// fails. A failed rule makes the address undeliverable, names itself in the
+// reason and reports true.
+func applyRules(email string, res *Result) bool {
+	if !res.SyntaxValid {
+		return false
+	}
+	rulesMu.RLock()
+	defer rulesMu.RUnlock()
+	for _, r := range rules {
+		if err := r.fn(email, res); err != nil {
+			Logger.Info("rule failed", "email", email, "rule", r.name, "error", err)
+			res.Status = Undeliverable
+			res.Reason = fmt.Sprintf("rule %s: %v", r.name, err)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package verifier
+
+// Score weights. They add up to 100 for an address that passes every check.
+const (
+	scoreSyntax        = 10 // well-formed address
+	scoreMX            = 20 // domain has a mail server
+	scoreSMTP          = 35 // server accepted the mailbox
+	scoreNotCatchAll   = 10 // ...and rejects mailboxes that do not exist
+	scoreNotDisposable = 10 // not a throwaway provider
+	scoreNotRoleBased  = 5  // a person rather than a shared role account
+	scoreSPF           = 5  // domain publishes SPF
+	scoreDMARC         = 5  // domain publishes DMARC
+)
+
+// score rates how likely mail to the address is to reach a real person, from
+// 0 to 100. It depends only on the result's fields, so equal results always
+// score the same. An invalid address always scores 0.
+func score(res Result) int {
+	if !res.SyntaxValid {
+		return 0
+	}
+	total := scoreSyntax
+	if len(res.MXRecords) > 0 {
+		total += scoreMX
+	}
+	if res.Status == Deliverable || res.Status == RiskyCatchAll {
+		total += scoreSMTP
+		if !res.CatchAll {
+			total += scoreNotCatchAll
+		}
+	}
+	if !res.Disposable {
+		total += scoreNotDisposable
+	}
+	if !res.RoleBased {
+		total += scoreNotRoleBased
+	}
+	if res.SPF != "" {
+		total += scoreSPF
+	}
+	if res.DMARC != "" {
+		total += scoreDMARC
+	}
+	return total
+}
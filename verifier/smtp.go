@@ -0,0 +1,705 @@
+package verifier
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// checkSMTP verifies if the email exists by talking to the domain's mail
+// servers and records the outcome on res
+func (v *Verifier) checkSMTP(ctx context.Context, email string, mxRecords []*net.MX, res *Result) error {
+	domain := res.ASCIIDomain
+	if err := v.waitForDomain(ctx, domain); err != nil {
+		res.Reason = "gave up waiting for the domain rate limit"
+		return err
+	}
+
+	start := time.Now()
+	defer func() { res.SMTPDuration = time.Since(start) }()
+
+	// Reuse an idle session with the domain's mail server when there is one,
+	// otherwise try the best mail servers in priority order until one
+	// completes the handshake
+	client := v.takeSession(ctx, domain)
+	var err error
+	for _, mx := range v.mxToTry(mxRecords) {
+		if client != nil || ctx.Err() != nil {
+			break
+		}
+		Logger.Info("trying mail server", "domain", domain, "host", mx.Host, "pref", mx.Pref)
+		client, _, err = v.connectMX(ctx, mx.Host)
+		if err != nil {
+			Logger.Warn("mail server unavailable", "host", mx.Host, "error", err)
+		}
+	}
+	if client == nil {
+		if err == nil {
+			err = ctx.Err()
+		}
+		res.Timeout = isTimeout(err)
+		res.Reason = connectReason(err)
+		// Asking again will not make the server offer TLS
+		if errors.Is(err, errNoSTARTTLS) {
+			return nil
+		}
+		if v.opts.Port == 25 && v.opts.Server == "" && v.Port25Blocked(ctx) {
+			res.Reason = "port 25 blocked locally"
+		}
+		return err
+	}
+	res.SMTPServer = client.host
+	res.SMTPPort = client.port
+	if state, ok := client.TLSConnectionState(); ok {
+		recordTLS(state, client.host, res)
+	}
+	res.TLSFailed = client.tlsFailed
+
+	// End with QUIT while the server is still talking to us, or keep the
+	// session for the next address at the domain; only a broken session is
+	// dropped without saying goodbye
+	broken := false
+	defer func() { v.releaseSession(domain, client, !broken) }()
+
+	if err = smtpCommand(client, "MAIL FROM:<%s>", client.from); err != nil {
+		err = atStage("MAIL FROM", err)
+		broken = smtpCode(err) == 0
+		res.Timeout = isTimeout(err)
+		recordReply(res, err)
+		res.Reason = replyReason("sender rejected", "MAIL FROM failed", err)
+		return err
+	}
+
+	// Probe a mailbox that cannot exist; if it is accepted, so is
+	// everything. Once one email at the domain has settled this, the rest
+	// skip the probe.
+	if v.opts.CheckCatchAll {
+		if catchAll, ok := v.knownCatchAll(domain); ok {
+			res.CatchAll = catchAll
+		} else {
+			err := smtpCommand(client, "RCPT TO:<%s>", randomLocalPart()+"@"+domain)
+			client.rcpts++
+			res.CatchAll = err == nil
+			if code := smtpCode(err); err == nil || code >= 500 {
+				v.rememberCatchAll(domain, res.CatchAll)
+			}
+		}
+	}
+
+	// Check recipient email; a permanent rejection is a definite answer, a
+	// transient one means try again later, and anything else means the
+	// conversation broke off
+	if err = v.rcpt(ctx, client, email); err != nil {
+		err = atStage("RCPT TO", err)
+		recordReply(res, err)
+		switch code := smtpCode(err); {
+		case code == 0:
+			broken = true
+			res.Timeout = isTimeout(err)
+			res.Reason = fmt.Sprintf("connection lost during RCPT TO: %v", err)
+			if res.Timeout {
+				res.Reason = "mail server timed out during RCPT TO"
+			}
+			return err
+		case isTransientCode(code):
+			if code == 452 {
+				// Too many recipients for this connection; do not reuse it
+				client.rcpts = v.opts.RcptPerConn
+			}
+			res.Status = Unknown
+			res.Reason = replyReason("greylisted", "", err)
+			return err
+		default:
+			res.Status = Undeliverable
+			res.Reason = replyReason("mailbox does not exist", "", err)
+			broken = v.probeVRFY(client, email, res)
+			return nil
+		}
+	}
+	broken = v.probeVRFY(client, email, res)
+
+	if name, ok := acceptAllProviderName(domain, res.MXRecords); ok {
+		res.Status = Unknown
+		res.Reason = fmt.Sprintf("SMTP verification is unreliable for %s, which accepts every recipient", name)
+		return nil
+	}
+	if !res.CatchAll {
+		broken = broken || v.probePlusAddressing(client, email, res)
+	}
+	if res.CatchAll {
+		res.Status = RiskyCatchAll
+		res.Reason = "catch-all domain, mailbox existence unknown"
+		return nil
+	}
+	res.Status = Deliverable
+	res.Reason = "mailbox exists"
+	return nil
+}
+
+// probePlusAddressing, when Options.CheckPlusAddressing is set, asks the
+// server to accept the mailbox with a random +tag. It only runs once the
+// plain mailbox was accepted at a domain that is not catch-all, where
+// acceptance means the server understands the tag. It reports whether the
+// session broke.
+func (v *Verifier) probePlusAddressing(client *session, email string, res *Result) bool {
+	if !v.opts.CheckPlusAddressing {
+		return false
+	}
+	at := strings.LastIndex(email, "@")
+	local := email[:at]
+	if strings.HasPrefix(local, `"`) {
+		return false
+	}
+	tag := randomLocalPart()
+	err := smtpCommand(client, "RCPT TO:<%s+%s%s>", stripTag(local), tag, email[at:])
+	client.rcpts++
+	res.PlusAddressing = err == nil
+	return err != nil && smtpCode(err) == 0
+}
+
+// probeVRFY records the server's answer to VRFY for the mailbox's local
+// part when Options.TryVRFY is set and the server advertises the command.
+// Servers usually answer 252 (cannot verify) or 502 (disabled), which are
+// recorded like any other reply. It reports whether the session broke.
+func (v *Verifier) probeVRFY(client *session, email string, res *Result) bool {
+	if !v.opts.TryVRFY {
+		return false
+	}
+	if ok, _ := client.Extension("VRFY"); !ok && !advertises(client.ehlo, "VRFY") {
+		return false
+	}
+	local := email[:strings.LastIndex(email, "@")]
+	client.arm()
+	id, err := client.Text.Cmd("VRFY %s", local)
+	if err != nil {
+		return true
+	}
+	client.Text.StartResponse(id)
+	defer client.Text.EndResponse(id)
+	code, msg, err := client.Text.ReadResponse(0)
+	Logger.Debug("smtp exchange", "command", "VRFY "+local, "code", code, "reply", msg)
+	if code == 0 && err != nil {
+		return true
+	}
+	res.VRFYCode = code
+	res.VRFYMessage = msg
+	return false
+}
+
+// endSession closes an SMTP session, politely with QUIT when graceful is
+// set. Servers log sessions dropped without QUIT as aborted, which can count
+// against the sending IP.
+func endSession(client *session, graceful bool) {
+	if graceful && client.Quit() == nil {
+		return
+	}
+	client.Close()
+}
+
+// rcpt issues RCPT TO, retrying with exponential backoff while the server
+// answers with a transient 4xx reply
+func (v *Verifier) rcpt(ctx context.Context, client *session, email string) error {
+	backoff := v.opts.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := smtpCommand(client, "RCPT TO:<%s>", email)
+		client.rcpts++
+		if err == nil || !isTransientCode(smtpCode(err)) || attempt >= v.opts.Retries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// smtpCommand sends a MAIL or RCPT command and waits for a 25x reply,
+// logging the exchange at debug level
+func smtpCommand(client *session, format string, args ...interface{}) error {
+	cmd := fmt.Sprintf(format, args...)
+	client.arm()
+	id, err := client.Text.Cmd("%s", cmd)
+	if err != nil {
+		return err
+	}
+	client.Text.StartResponse(id)
+	defer client.Text.EndResponse(id)
+	code, msg, err := client.Text.ReadResponse(25)
+	Logger.Debug("smtp exchange", "command", cmd, "code", code, "reply", msg)
+	return err
+}
+
+// replyReason describes a failed command: "<rejected> (<code>): <message>"
+// when the server replied, or "<failed>: <error>" when it broke off
+func replyReason(rejected, failed string, err error) string {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return fmt.Sprintf("%s (%d): %s", rejected, protoErr.Code, protoErr.Msg)
+	}
+	if failed == "" {
+		failed = rejected
+	}
+	return fmt.Sprintf("%s: %v", failed, err)
+}
+
+// connectReason describes why no mail server could be reached
+func connectReason(err error) string {
+	switch {
+	case errors.Is(err, errNoSTARTTLS):
+		return "TLS required but the mail server does not offer STARTTLS"
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "mail server refused connection"
+	case isTimeout(err):
+		if stage := timedOutStage(err); stage != "" && stage != "TCP connect" {
+			return "mail server timed out during " + stage
+		}
+		return "connection timed out (possibly filtered)"
+	default:
+		return fmt.Sprintf("could not connect to mail server: %v", err)
+	}
+}
+
+// mxToTry returns the mail servers worth connecting to, at most
+// Options.MaxMX of them. Lookups return hosts sorted by preference.
+func (v *Verifier) mxToTry(hosts []*net.MX) []*net.MX {
+	if v.opts.MaxMX > 0 && len(hosts) > v.opts.MaxMX {
+		return hosts[:v.opts.MaxMX]
+	}
+	return hosts
+}
+
+// smtpCode extracts the reply code from an SMTP error, or 0 if the error
+// did not come from a server reply
+func smtpCode(err error) int {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return protoErr.Code
+	}
+	return 0
+}
+
+// recordReply stores the server's reply code and message from an SMTP
+// error on the result
+func recordReply(res *Result, err error) {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		res.SMTPCode = protoErr.Code
+		res.SMTPMessage = protoErr.Msg
+	}
+}
+
+// isTransientCode reports whether an SMTP reply code is a 4xx temporary
+// failure
+func isTransientCode(code int) bool {
+	return code >= 400 && code < 500
+}
+
+// randomLocalPart builds a local part that is vanishingly unlikely to exist
+func randomLocalPart() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return "nonexistent-" + hex.EncodeToString(b)
+}
+
+// sender returns the MAIL FROM address to use
+func (v *Verifier) sender() string {
+	if v.opts.FromAddr != "" {
+		return v.opts.FromAddr
+	}
+	domain := v.opts.FromDomain
+	if domain == "" {
+		domain = strings.TrimSuffix(v.heloName(), ".")
+	}
+	return "verify@" + domain
+}
+
+// Sender returns the MAIL FROM address used when Options.FromPool is
+// empty, for callers that want to check it is a plausible one
+func (v *Verifier) Sender() string {
+	return v.sender()
+}
+
+// rotatedSender returns the MAIL FROM address for the nth connection,
+// taking the local part from Options.FromPool when it is set
+func (v *Verifier) rotatedSender(n uint64) string {
+	sender, pool := v.sender(), v.opts.FromPool
+	if len(pool) == 0 {
+		return sender
+	}
+	return pool[n%uint64(len(pool))] + sender[strings.LastIndex(sender, "@"):]
+}
+
+// rotatedHelo returns the EHLO/HELO hostname for the nth connection,
+// taking it from Options.HeloPool when it is set
+func (v *Verifier) rotatedHelo(n uint64) string {
+	if pool := v.opts.HeloPool; len(pool) > 0 {
+		return pool[n%uint64(len(pool))]
+	}
+	return v.heloName()
+}
+
+// heloName returns the hostname to greet mail servers with
+func (v *Verifier) heloName() string {
+	if v.opts.HeloName != "" {
+		return v.opts.HeloName
+	}
+	if host, err := os.Hostname(); err == nil && host != "" {
+		return host
+	}
+	return "localhost"
+}
+
+// probeAddr is a mail server that always listens on port 25, used to tell
+// a dead mail server apart from a network that blocks outbound SMTP
+const probeAddr = "gmail-smtp-in.l.google.com:25"
+
+// Port25Blocked reports whether outbound connections to port 25 fail, as
+// they do on many residential and cloud networks, in which case SMTP
+// checks cannot reach any mail server. The probe runs once per Verifier,
+// with its own deadline of Options.ConnectTimeout (5s when unset) so that
+// a caller whose context is already done cannot make it fail.
+func (v *Verifier) Port25Blocked(ctx context.Context) bool {
+	v.probeOnce.Do(func() {
+		timeout := v.opts.ConnectTimeout
+		if timeout <= 0 {
+			timeout = 5 * time.Second
+		}
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+		defer cancel()
+		conn, err := v.dialContext(ctx, probeAddr)
+		if err != nil {
+			Logger.Warn("port 25 probe failed", "addr", probeAddr, "error", err)
+			v.port25Closed = true
+			return
+		}
+		conn.Close()
+	})
+	return v.port25Closed
+}
+
+// server splits Options.Server into a host and port, using Options.Port
+// when it names no port
+func (v *Verifier) server() (string, int) {
+	host, portText, err := net.SplitHostPort(v.opts.Server)
+	if err != nil {
+		return v.opts.Server, v.opts.Port
+	}
+	port, err := strconv.Atoi(portText)
+	if err != nil {
+		return host, v.opts.Port
+	}
+	return host, port
+}
+
+// candidatePorts lists the ports to try on a mail server, in order
+func (v *Verifier) candidatePorts() []int {
+	first := v.opts.Port
+	if v.opts.Server != "" {
+		_, first = v.server()
+	}
+	ports := []int{first}
+	if v.opts.PortFallback {
+		for _, p := range []int{587, 465} {
+			if p != first {
+				ports = append(ports, p)
+			}
+		}
+	}
+	return ports
+}
+
+// connectMX opens an SMTP session with a mail server, moving on to the
+// fallback ports only when the previous port timed out
+func (v *Verifier) connectMX(ctx context.Context, mx string) (*session, int, error) {
+	var err error
+	for _, port := range v.candidatePorts() {
+		var client *session
+		client, err = v.dialRetry(ctx, mx, port, v.opts.TLSPolicy)
+		// A broken STARTTLS says nothing about the mailbox, so start over
+		// in plaintext unless TLS was demanded
+		if errors.Is(err, errSTARTTLS) && v.opts.TLSPolicy == TLSOpportunistic && !v.opts.StrictTLS {
+			Logger.Warn("starttls failed, retrying without tls", "host", mx, "port", port, "error", err)
+			if client, err = v.dialRetry(ctx, mx, port, TLSNone); err == nil {
+				client.tlsFailed = true
+			}
+		}
+		Logger.Debug("smtp connect", "host", mx, "port", port, "error", err)
+		if err == nil {
+			return client, port, nil
+		}
+		if !isTimeout(err) {
+			break
+		}
+	}
+	return nil, 0, err
+}
+
+// dialRetry is dialSMTP, retried with exponential backoff while it fails
+// with a transient network error
+func (v *Verifier) dialRetry(ctx context.Context, mx string, port int, policy TLSPolicy) (*session, error) {
+	backoff := v.opts.ConnectBackoff
+	for attempt := 0; ; attempt++ {
+		client, err := v.dialSMTP(ctx, mx, port, policy)
+		if err == nil || !isTransientNetError(err) || attempt >= v.opts.ConnectRetries {
+			return client, err
+		}
+		Logger.Debug("retrying smtp connect", "host", mx, "port", port, "attempt", attempt+1, "error", err)
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// isTransientNetError reports whether err is a network failure worth
+// retrying: a timeout, or a connection reset or cut off mid-handshake. A
+// refused connection or any SMTP reply is an answer, not a hiccup.
+func isTransientNetError(err error) bool {
+	if smtpCode(err) != 0 || errors.Is(err, errNoSTARTTLS) {
+		return false
+	}
+	return isTimeout(err) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// session is an open SMTP conversation with one mail server
+type session struct {
+	*smtp.Client
+	conn      net.Conn // underlying TCP connection, for deadlines
+	ctx       context.Context
+	timeout   time.Duration // per-command limit, see Options.CommandTimeout
+	host      string
+	port      int
+	from      string    // MAIL FROM address, see Options.FromPool
+	ehlo      []string  // the server's reply to EHLO, before any STARTTLS
+	tlsFailed bool      // STARTTLS failed, so the session is in plaintext
+	rcpts     int       // RCPT TO commands sent so far
+	idle      time.Time // when the session was last returned to the pool
+}
+
+// arm gives the next command the session's timeout to complete, never
+// extending past the deadline of the session's context
+func (s *session) arm() {
+	s.armFor(s.timeout)
+}
+
+// armFor is like arm with a timeout of d instead
+func (s *session) armFor(d time.Duration) {
+	var deadline time.Time
+	if d > 0 {
+		deadline = time.Now().Add(d)
+	}
+	if d, ok := s.ctx.Deadline(); ok && (deadline.IsZero() || d.Before(deadline)) {
+		deadline = d
+	}
+	s.conn.SetDeadline(deadline)
+}
+
+// dialSMTP connects to a mail server and completes the SMTP handshake.
+// Port 465 speaks TLS from the start, every other port is upgraded with
+// STARTTLS as policy allows. Each stage runs under its own deadline so a
+// stalled server cannot hold the session open.
+func (v *Verifier) dialSMTP(ctx context.Context, mx string, port int, policy TLSPolicy) (*session, error) {
+	addr := net.JoinHostPort(mx, strconv.Itoa(port))
+	conn, err := v.dialContext(ctx, addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, atStage("TCP connect", err))
+	}
+	n := v.rotation.Add(1) - 1
+	s := &session{conn: conn, ctx: ctx, timeout: v.opts.CommandTimeout, host: mx, port: port, from: v.rotatedSender(n)}
+	s.arm()
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: !v.opts.StrictTLS,
+		ServerName:         strings.TrimSuffix(mx, "."),
+	}
+	var wire net.Conn = conn
+	if port == 465 {
+		tlsConn := tls.Client(conn, tlsConfig)
+		s.armFor(v.tlsTimeout())
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("TLS handshake with %s failed: %w", addr, atStage("TLS handshake", err))
+		}
+		s.arm()
+		wire = tlsConn
+	} else if v.opts.Trace != nil {
+		wire = &traceConn{Conn: conn, host: mx, trace: v.opts.Trace}
+	}
+
+	client, err := smtp.NewClient(wire, mx)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create SMTP client for %s: %w", addr, atStage("SMTP greeting", err))
+	}
+	s.Client = client
+	if port == 465 && v.opts.Trace != nil {
+		traceText(client.Text, mx, v.opts.Trace)
+	}
+
+	// Hello falls back to HELO when EHLO is refused. The EHLO reply is
+	// recorded so capabilities that net/smtp misparses are still found.
+	var ehlo []string
+	recording := true
+	traceText(client.Text, mx, func(_ string, sent bool, line string) {
+		if recording && !sent {
+			ehlo = append(ehlo, line)
+		}
+	})
+	s.arm()
+	err = client.Hello(v.rotatedHelo(n))
+	recording = false
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("EHLO and HELO rejected by %s: %w", addr, atStage("EHLO", err))
+	}
+	s.ehlo = ehlo
+
+	// Upgrade with STARTTLS as the TLS policy asks
+	if port != 465 && policy != TLSNone {
+		ok, _ := client.Extension("STARTTLS")
+		ok = ok || advertises(ehlo, "STARTTLS")
+		if !ok && policy == TLSRequired {
+			client.Close()
+			return nil, fmt.Errorf("%s: %w", addr, errNoSTARTTLS)
+		}
+		if ok {
+			s.armFor(v.tlsTimeout())
+			if err = client.StartTLS(tlsConfig); err != nil {
+				client.Close()
+				return nil, fmt.Errorf("%w with %s: %w", errSTARTTLS, addr, atStage("TLS handshake", err))
+			}
+			// StartTLS replaced the text connection; keep tracing above TLS.
+			// The EHLO it repeats internally goes unseen.
+			if v.opts.Trace != nil {
+				traceText(client.Text, mx, v.opts.Trace)
+			}
+		}
+	}
+	return s, nil
+}
+
+// tlsTimeout is the time allowed for a TLS handshake
+func (v *Verifier) tlsTimeout() time.Duration {
+	if v.opts.TLSTimeout > 0 {
+		return v.opts.TLSTimeout
+	}
+	return v.opts.CommandTimeout
+}
+
+// stageError records the step of a verification an error happened in, so
+// a timeout can say which step ran out of time
+type stageError struct {
+	stage string
+	err   error
+}
+
+func (e *stageError) Error() string { return e.err.Error() }
+func (e *stageError) Unwrap() error { return e.err }
+
+// atStage tags a non-nil err with the step it happened in
+func atStage(stage string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &stageError{stage: stage, err: err}
+}
+
+// timedOutStage returns the step err timed out in, or "" when it is not a
+// timeout or the step is not known
+func timedOutStage(err error) string {
+	var se *stageError
+	if isTimeout(err) && errors.As(err, &se) {
+		return se.stage
+	}
+	return ""
+}
+
+// errSTARTTLS means the STARTTLS handshake with a mail server failed
+var errSTARTTLS = errors.New("failed to start TLS")
+
+// errNoSTARTTLS means a mail server does not offer the STARTTLS that
+// TLSRequired demands
+var errNoSTARTTLS = errors.New("server does not offer STARTTLS")
+
+// advertises reports whether an EHLO reply lists the extension. Unlike
+// smtp.Client.Extension it matches keywords in any case and on any line,
+// including the first, for servers that wrap or format the list oddly.
+func advertises(reply []string, ext string) bool {
+	for _, line := range reply {
+		if len(line) < 4 || !strings.HasPrefix(line, "250") {
+			continue
+		}
+		fields := strings.Fields(line[4:])
+		for _, field := range fields {
+			if strings.EqualFold(field, ext) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// recordTLS stores the negotiated TLS version and whether the server's
+// certificate is valid for host, even when verification was skipped
+func recordTLS(state tls.ConnectionState, host string, res *Result) {
+	res.TLSVersion = tlsVersionName(state.Version)
+	if len(state.PeerCertificates) == 0 {
+		return
+	}
+	leaf := state.PeerCertificates[0]
+	res.TLSExpiry = leaf.NotAfter
+
+	opts := x509.VerifyOptions{
+		DNSName:       strings.TrimSuffix(host, "."),
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range state.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err := leaf.Verify(opts)
+	res.TLSValid = err == nil
+}
+
+// tlsVersionName returns a readable name for a TLS protocol version
+func tlsVersionName(version uint16) string {
+	switch version {
+	case tls.VersionTLS10:
+		return "TLS 1.0"
+	case tls.VersionTLS11:
+		return "TLS 1.1"
+	case tls.VersionTLS12:
+		return "TLS 1.2"
+	case tls.VersionTLS13:
+		return "TLS 1.3"
+	default:
+		return fmt.Sprintf("0x%04x", version)
+	}
+}
+
+// isTimeout reports whether err was caused by a network timeout
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,340 @@
+package verifier
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeResolver answers MX lookups for every domain with mx.<domain>, and
+// address lookups with 127.0.0.1, without touching the network. Names in
+// missing do not exist. It records the MX lookups it was asked for.
+type fakeResolver struct {
+	missing map[string]bool
+
+	mu      sync.Mutex
+	lookups []string
+}
+
+func notFound(name string) error {
+	return &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+func (r *fakeResolver) LookupMX(_ context.Context, name string) ([]*net.MX, error) {
+	r.mu.Lock()
+	r.lookups = append(r.lookups, name)
+	r.mu.Unlock()
+	if r.missing[name] {
+		return nil, notFound(name)
+	}
+	return []*net.MX{{Host: "mx." + name, Pref: 10}}, nil
+}
+
+func (r *fakeResolver) LookupIPAddr(_ context.Context, host string) ([]net.IPAddr, error) {
+	if r.missing[host] {
+		return nil, notFound(host)
+	}
+	return []net.IPAddr{{IP: net.IPv4(127, 0, 0, 1)}}, nil
+}
+
+func (r *fakeResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	return nil, notFound(name)
+}
+
+// mockScript decides how a mockSMTP server answers
+type mockScript struct {
+	// greeting is the 220 banner, one line each; nil sends a single line
+	greeting []string
+	// ehlo is the reply to EHLO, one line each; nil advertises nothing
+	ehlo []string
+	// starttls is the reply to STARTTLS; empty means 502
+	starttls string
+	// rcpt returns the reply to RCPT TO for the address and how many times
+	// it was sent before; an empty reply makes the server go silent
+	rcpt func(addr string, attempt int) string
+}
+
+// mockSMTP is a scripted SMTP server listening on a local port
+type mockSMTP struct {
+	ln     net.Listener
+	script mockScript
+
+	mu    sync.Mutex
+	rcpts []string // every RCPT TO address, in order
+	helos []string // every EHLO or HELO command
+}
+
+// startMockSMTP starts a server answering as script says; it stops when
+// the test ends
+func startMockSMTP(t testing.TB, script mockScript) *mockSMTP {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	m := &mockSMTP{ln: ln, script: script}
+	done := make(chan struct{})
+	t.Cleanup(func() {
+		close(done)
+		ln.Close()
+	})
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go m.serve(conn, done)
+		}
+	}()
+	return m
+}
+
+// dial connects to the mock server whatever address is asked for, standing
+// in for the verifier's TCP dialer
+func (m *mockSMTP) dial(ctx context.Context, network, _ string) (net.Conn, error) {
+	var d net.Dialer
+	return d.DialContext(ctx, network, m.ln.Addr().String())
+}
+
+// rcptCount returns how many RCPT TO commands named addr
+func (m *mockSMTP) rcptCount(addr string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for _, a := range m.rcpts {
+		if a == addr {
+			n++
+		}
+	}
+	return n
+}
+
+func (m *mockSMTP) serve(conn net.Conn, done <-chan struct{}) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(lines ...string) {
+		for _, line := range lines {
+			conn.Write([]byte(line + "\r\n"))
+		}
+	}
+	if m.script.greeting == nil {
+		reply("220 mock.test ESMTP ready")
+	} else {
+		reply(m.script.greeting...)
+	}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO":
+			m.mu.Lock()
+			m.helos = append(m.helos, line)
+			m.mu.Unlock()
+			if m.script.ehlo == nil {
+				reply("250 mock.test")
+			} else {
+				reply(m.script.ehlo...)
+			}
+		case "HELO":
+			m.mu.Lock()
+			m.helos = append(m.helos, line)
+			m.mu.Unlock()
+			reply("250 mock.test")
+		case "STARTTLS":
+			if m.script.starttls == "" {
+				reply("502 5.5.1 STARTTLS not supported")
+			} else {
+				reply(m.script.starttls)
+			}
+		case "MAIL", "RSET", "NOOP":
+			reply("250 2.0.0 OK")
+		case "RCPT":
+			addr := line[strings.Index(line, "<")+1 : strings.LastIndex(line, ">")]
+			m.mu.Lock()
+			attempt := 0
+			for _, a := range m.rcpts {
+				if a == addr {
+					attempt++
+				}
+			}
+			m.rcpts = append(m.rcpts, addr)
+			m.mu.Unlock()
+			answer := m.script.rcpt(addr, attempt)
+			if answer == "" {
+				// Go silent, like a tarpit, until the client gives up
+				select {
+				case <-done:
+				case <-time.After(10 * time.Second):
+				}
+				return
+			}
+			reply(answer)
+		case "QUIT":
+			reply("221 2.0.0 bye")
+			return
+		default:
+			reply("502 5.5.2 command not recognized")
+		}
+	}
+}
+
+// mockOptions returns options that send every lookup to a fake resolver
+// and every connection to the mock server
+func mockOptions(m *mockSMTP) Options {
+	opts := DefaultOptions()
+	opts.Transport = Transport{Dial: m.dial, Resolver: &fakeResolver{}}
+	opts.FromDomain = "sender.mail-test.org"
+	opts.HeloName = "probe.mail-test.org"
+	opts.Retries = 0
+	opts.RetryBackoff = 10 * time.Millisecond
+	opts.CheckCatchAll = false
+	return opts
+}
+
+// acceptOnly accepts the given mailbox and rejects every other one
+func acceptOnly(mailbox string) func(string, int) string {
+	return func(addr string, _ int) string {
+		if addr == mailbox {
+			return "250 2.1.5 OK"
+		}
+		return "550 5.1.1 no such user"
+	}
+}
+
+func TestCheckSMTPDeliverable(t *testing.T) {
+	m := startMockSMTP(t, mockScript{rcpt: acceptOnly("alice@mail-test.org")})
+	v := New(mockOptions(m))
+	defer v.Close()
+
+	res, err := v.Verify(context.Background(), "alice@mail-test.org")
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if res.Status != Deliverable {
+		t.Errorf("Status = %q, want %q (reason %q)", res.Status, Deliverable, res.Reason)
+	}
+	if res.SMTPServer != "mx.mail-test.org" {
+		t.Errorf("SMTPServer = %q, want mx.mail-test.org", res.SMTPServer)
+	}
+}
+
+func TestCheckSMTPRejected(t *testing.T) {
+	m := startMockSMTP(t, mockScript{rcpt: acceptOnly("alice@mail-test.org")})
+	v := New(mockOptions(m))
+	defer v.Close()
+
+	res, err := v.Verify(context.Background(), "bob@mail-test.org")
+	if err != nil {
+		t.Fatalf("a rejected mailbox is a definite answer, got error %v", err)
+	}
+	if res.Status != Undeliverable {
+		t.Errorf("Status = %q, want %q", res.Status, Undeliverable)
+	}
+	if res.SMTPCode != 550 {
+		t.Errorf("SMTPCode = %d, want 550", res.SMTPCode)
+	}
+}
+
+func TestCheckSMTPCatchAll(t *testing.T) {
+	tests := []struct {
+		name     string
+		rcpt     func(string, int) string
+		catchAll bool
+		want     Status
+	}{
+		{"accepts everything", func(string, int) string { return "250 OK" }, true, RiskyCatchAll},
+		{"rejects the probe", acceptOnly("alice@mail-test.org"), false, Deliverable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := startMockSMTP(t, mockScript{rcpt: tt.rcpt})
+			opts := mockOptions(m)
+			opts.CheckCatchAll = true
+			v := New(opts)
+			defer v.Close()
+
+			res, err := v.Verify(context.Background(), "alice@mail-test.org")
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if res.CatchAll != tt.catchAll || res.Status != tt.want {
+				t.Errorf("CatchAll, Status = %v, %q, want %v, %q", res.CatchAll, res.Status, tt.catchAll, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckSMTPGreylisted(t *testing.T) {
+	// Greylisting servers accept the retry once enough time has passed
+	acceptThird := func(_ string, attempt int) string {
+		if attempt < 2 {
+			return "451 4.7.1 greylisted, try again later"
+		}
+		return "250 2.1.5 OK"
+	}
+	alwaysGrey := func(string, int) string { return "451 4.7.1 greylisted, try again later" }
+
+	tests := []struct {
+		name     string
+		rcpt     func(string, int) string
+		retries  int
+		want     Status
+		attempts int
+		wantErr  bool
+	}{
+		{"accepted on retry", acceptThird, 2, Deliverable, 3, false},
+		{"retries exhausted", alwaysGrey, 1, Unknown, 2, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := startMockSMTP(t, mockScript{rcpt: tt.rcpt})
+			opts := mockOptions(m)
+			opts.Retries = tt.retries
+			v := New(opts)
+			defer v.Close()
+
+			res, err := v.Verify(context.Background(), "alice@mail-test.org")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if res.Status != tt.want {
+				t.Errorf("Status = %q, want %q (reason %q)", res.Status, tt.want, res.Reason)
+			}
+			if n := m.rcptCount("alice@mail-test.org"); n != tt.attempts {
+				t.Errorf("RCPT TO sent %d times, want %d", n, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestCheckSMTPTimeout(t *testing.T) {
+	m := startMockSMTP(t, mockScript{rcpt: func(string, int) string { return "" }})
+	opts := mockOptions(m)
+	opts.CommandTimeout = 200 * time.Millisecond
+	v := New(opts)
+	defer v.Close()
+
+	start := time.Now()
+	res, err := v.Verify(context.Background(), "alice@mail-test.org")
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("a silent server held the check for %s", elapsed)
+	}
+	if err == nil {
+		t.Fatal("a silent server is a transient failure, got nil error")
+	}
+	if !res.Timeout || res.Status != Timeout {
+		t.Errorf("Timeout, Status = %v, %q, want true, %q", res.Timeout, res.Status, Timeout)
+	}
+	if !strings.Contains(res.Reason, "RCPT TO") {
+		t.Errorf("Reason = %q, want it to name the RCPT TO step", res.Reason)
+	}
+}
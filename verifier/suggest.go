@@ -0,0 +1,86 @@
+package verifier
+
+import (
+	"strings"
+)
+
+// popularDomains are the mail domains typos are most often made against
+var popularDomains = []string{
+	"gmail.com",
+	"googlemail.com",
+	"yahoo.com",
+	"ymail.com",
+	"hotmail.com",
+	"outlook.com",
+	"live.com",
+	"msn.com",
+	"icloud.com",
+	"me.com",
+	"aol.com",
+	"mail.com",
+	"gmx.com",
+	"gmx.de",
+	"protonmail.com",
+	"proton.me",
+	"zoho.com",
+	"yandex.com",
+	"comcast.net",
+	"verizon.net",
+}
+
+// maxSuggestDistance is the largest edit distance still treated as a typo
+const maxSuggestDistance = 2
+
+// suggestDomain returns the popular domain closest to domain when it looks
+// like a misspelling of one. Short domains tolerate fewer edits so that
+// unrelated names like acme.com are not mistaken for me.com.
+func suggestDomain(domain string) (string, bool) {
+	domain = strings.ToLower(domain)
+	best, bestDist := "", maxSuggestDistance+1
+	for _, candidate := range popularDomains {
+		if candidate == domain {
+			return "", false
+		}
+		allowed := len(candidate) / 4
+		if allowed > maxSuggestDistance {
+			allowed = maxSuggestDistance
+		}
+		if d := levenshtein(domain, candidate); d <= allowed && d < bestDist {
+			best, bestDist = candidate, d
+		}
+	}
+	return best, best != ""
+}
+
+// levenshtein computes the edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// min3 returns the smallest of three ints
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
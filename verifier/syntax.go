@@ -0,0 +1,83 @@
+package verifier
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+// Length limits from RFC 5321 section 4.5.3.1
+const (
+	maxLocalPartLength = 64
+	maxAddressLength   = 254
+)
+
+// validateLength checks the address and its local part against the octet
+// limits of RFC 5321
+func validateLength(email string) error {
+	if len(email) > maxAddressLength {
+		return fmt.Errorf("address is %d octets, more than the %d allowed", len(email), maxAddressLength)
+	}
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return errors.New("missing @")
+	}
+	if n := len(email[:at]); n > maxLocalPartLength {
+		return fmt.Errorf("local part is %d octets, more than the %d allowed", n, maxLocalPartLength)
+	}
+	return nil
+}
+
+// validateEmail checks the syntax of a plain email address and explains
+// what is wrong with it. On top of mail.ParseAddress it rejects display
+// names and angle brackets, domains without a dot, misplaced dots in an
+// unquoted local part, and parts longer than RFC 5321 allows.
+//
+// Accepted are dot-atom local parts with any of the RFC 5322 atext
+// symbols, so plus tags like john+tag and forms like user%host, and quoted
+// local parts such as "john doe" or "john..doe", which may contain an @.
+// Domains must be host names; address literals like [192.0.2.1] are
+// rejected since they have no MX records to check.
+func validateEmail(email string) error {
+	if err := validateLength(email); err != nil {
+		return err
+	}
+	at := strings.LastIndex(email, "@")
+	local, domain := email[:at], email[at+1:]
+
+	// Dots only matter outside quotes; "john..doe"@example.com is legal
+	quoted := strings.HasPrefix(local, `"`)
+	if !quoted {
+		if strings.HasPrefix(local, ".") || strings.HasSuffix(local, ".") {
+			return errors.New("local part starts or ends with a dot")
+		}
+		if strings.Contains(local, "..") {
+			return errors.New("local part has consecutive dots")
+		}
+	}
+
+	addr, err := mail.ParseAddress(email)
+	if err != nil {
+		return errors.New(strings.TrimPrefix(err.Error(), "mail: "))
+	}
+	// ParseAddress also accepts "Name <addr>" forms, so insist the parsed
+	// address is the whole input. A quoted local part may come back
+	// unquoted, so for those only the brackets and name are checked.
+	if addr.Name != "" || strings.HasPrefix(email, "<") || (!quoted && addr.Address != email) {
+		return errors.New("display names and angle brackets are not allowed")
+	}
+
+	if strings.HasPrefix(domain, "[") {
+		return fmt.Errorf("address literal %s is not supported", domain)
+	}
+	if !strings.Contains(strings.Trim(domain, "."), ".") {
+		return fmt.Errorf("domain %q is not fully qualified", domain)
+	}
+	for _, label := range strings.Split(strings.TrimSuffix(domain, "."), ".") {
+		if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return fmt.Errorf("domain label %q starts or ends with a hyphen", label)
+		}
+	}
+	return nil
+}
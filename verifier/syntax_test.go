@@ -0,0 +1,109 @@
+package verifier
+
+import (
+	"strings"
+	"testing"
+)
+
+// domainOfLength returns a domain of n octets, n being at least 193, made
+// of three full 63-octet labels and a shorter last one
+func domainOfLength(n int) string {
+	label := strings.Repeat("a", 63)
+	return label + "." + label + "." + label + "." + strings.Repeat("b", n-192)
+}
+
+func TestValidateEmailStrict(t *testing.T) {
+	tests := []struct {
+		email   string
+		wantErr string // substring of the error, or "" for a valid address
+	}{
+		{"john@example.com", ""},
+		{"John Doe <john@example.com>", "display names"},
+		{"<john@example.com>", "display names"},
+		{`"a"@b`, "not fully qualified"},
+		{"john@localhost", "not fully qualified"},
+		{"john..doe@example.com", "consecutive dots"},
+		{".john@example.com", "starts or ends with a dot"},
+		{"john.@example.com", "starts or ends with a dot"},
+		{strings.Repeat("a", 64) + "@example.com", ""},
+		{strings.Repeat("a", 65) + "@example.com", "local part is 65 octets"},
+		{"a@" + domainOfLength(252), ""},
+		{"a@" + domainOfLength(253), "address is 255 octets"},
+		{"john.example.com", "missing @"},
+	}
+	for _, tt := range tests {
+		err := validateEmail(tt.email)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("validateEmail(%q) = %v, want nil", tt.email, err)
+		case tt.wantErr != "" && err == nil:
+			t.Errorf("validateEmail(%q) = nil, want an error containing %q", tt.email, tt.wantErr)
+		case tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr):
+			t.Errorf("validateEmail(%q) = %v, want an error containing %q", tt.email, err, tt.wantErr)
+		}
+	}
+}
+
+// TestValidateEmail pins down which forms validateEmail accepts, using
+// examples from RFC 5321, RFC 5322 and RFC 3696
+func TestValidateEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		valid bool
+	}{
+		// Dot-atom local parts, with tags and any atext symbol
+		{"simple@example.com", true},
+		{"very.common@example.com", true},
+		{"x@example.com", true},
+		{"john+tag@example.com", true},
+		{"user.name+tag+sorting@example.com", true},
+		{"other.email-with-hyphen@example.com", true},
+		{"fully-qualified-domain@example.com", true},
+		{"example-indeed@strange-example.com", true},
+		{"admin@mailserver1.example.org", true},
+		{"mailhost!username@example.org", true},
+		{"user%example.com@example.org", true},
+		{"user-@example.org", true},
+		{"#!$%&'*+-/=?^_`{}|~@example.org", true},
+
+		// Quoted local parts, which may hold spaces, dots and @
+		{`"john doe"@example.com`, true},
+		{`"john..doe"@example.com`, true},
+		{`" "@example.org`, true},
+		{`"john@doe"@example.com`, true},
+		{`"very.(),:;<>[]\".VERY.\"very@\\ \"very\".unusual"@strange.example.com`, true},
+
+		// Address literals have no MX records to check
+		{"user@[192.168.2.1]", false},
+		{"user@[IPv6:2001:db8::1]", false},
+
+		// Malformed
+		{"Abc.example.com", false},
+		{"A@b@c@example.com", false},
+		{`a"b(c)d,e:f;g<h>i[j\k]l@example.com`, false},
+		{`just"not"right@example.com`, false},
+		{`this is"not\allowed@example.com`, false},
+		{`this\ still\"not\\allowed@example.com`, false},
+		{`"unclosed@example.com`, false},
+		{"john doe@example.com", false},
+		{"john..doe@example.com", false},
+		{".john@example.com", false},
+		{"john.@example.com", false},
+		{"john@localhost", false},
+		{"john@example..com", false},
+		{"john@-example.com", false},
+		{"john@example-.com", false},
+		{"john@example.com.", false}, // a root dot is DNS syntax, not RFC 5321
+		{"John Doe <john@example.com>", false},
+		{"<john@example.com>", false},
+		{"john@", false},
+		{"@example.com", false},
+		{strings.Repeat("a", 64) + "+x@example.com", false},
+	}
+	for _, tt := range tests {
+		err := validateEmail(tt.email)
+		if (err == nil) != tt.valid {
+			t.Errorf("validateEmail(%q) = %v, want valid %v", tt.email, err, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package verifier
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/textproto"
+	"strings"
+)
+
+// TraceFunc receives each line of an SMTP conversation with host, sent
+// reporting whether it went from us to the server
+type TraceFunc func(host string, sent bool, line string)
+
+// traceConn echoes the plaintext part of an SMTP conversation, up to the
+// server's go-ahead for STARTTLS, after which the bytes are encrypted
+type traceConn struct {
+	net.Conn
+	host     string
+	trace    TraceFunc
+	starting bool // STARTTLS sent, waiting for the 220 reply
+	off      bool
+}
+
+func (c *traceConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if !c.off {
+		for _, line := range traceLines(p[:n]) {
+			c.trace(c.host, false, line)
+			if c.starting && strings.HasPrefix(line, "220") {
+				c.off = true
+			}
+		}
+	}
+	return n, err
+}
+
+func (c *traceConn) Write(p []byte) (int, error) {
+	if !c.off {
+		for _, line := range traceLines(p) {
+			c.trace(c.host, true, line)
+			c.starting = strings.EqualFold(line, "STARTTLS")
+		}
+	}
+	return c.Conn.Write(p)
+}
+
+// traceText echoes everything a client reads and writes from now on by
+// wrapping the buffers of its textproto connection, which sit above TLS
+func traceText(text *textproto.Conn, host string, trace TraceFunc) {
+	text.Reader.R = bufio.NewReader(traceReader{text.Reader.R, host, trace})
+	text.Writer.W = bufio.NewWriter(traceWriter{text.Writer.W, host, trace})
+}
+
+// traceReader echoes lines read from r
+type traceReader struct {
+	r     io.Reader
+	host  string
+	trace TraceFunc
+}
+
+func (t traceReader) Read(p []byte) (int, error) {
+	n, err := t.r.Read(p)
+	for _, line := range traceLines(p[:n]) {
+		t.trace(t.host, false, line)
+	}
+	return n, err
+}
+
+// traceWriter echoes lines written to w, flushing w so that each command
+// reaches the server when the client flushes
+type traceWriter struct {
+	w     *bufio.Writer
+	host  string
+	trace TraceFunc
+}
+
+func (t traceWriter) Write(p []byte) (int, error) {
+	for _, line := range traceLines(p) {
+		t.trace(t.host, true, line)
+	}
+	n, err := t.w.Write(p)
+	if err == nil {
+		err = t.w.Flush()
+	}
+	return n, err
+}
+
+// traceLines splits a chunk of SMTP traffic into its non-empty lines
+func traceLines(p []byte) []string {
+	var lines []string
+	for _, line := range strings.Split(string(p), "\n") {
+		if line = strings.TrimRight(line, "\r"); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,25 @@
+package verifier
+
+import (
+	"context"
+	"net"
+)
+
+// DialFunc opens a network connection, with the same signature as
+// net.Dialer.DialContext
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// Resolver is the subset of *net.Resolver the verifier uses for DNS lookups
+type Resolver interface {
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// Transport holds the network dependencies of a Verifier. Nil fields fall
+// back to the defaults: a direct TCP dial (through Options.ProxyURL when
+// set) and the system resolver (or Options.DNSServer when set).
+type Transport struct {
+	Dial     DialFunc
+	Resolver Resolver
+}
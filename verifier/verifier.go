@@ -0,0 +1,255 @@
+// Package verifier checks whether an email address is well formed, whether
+// its domain has a mail server, and whether that server accepts the mailbox.
+package verifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Status is the overall verdict on an address, derived from every check
+// that ran. It is the field most callers should switch on.
+type Status string
+
+const (
+	// Deliverable means the server accepted the mailbox and rejects unknown ones
+	Deliverable Status = "deliverable"
+	// Undeliverable means the server refused the mailbox or the domain
+	// accepts no mail
+	Undeliverable Status = "undeliverable"
+	// RiskyCatchAll means the server accepts every recipient, so the
+	// mailbox may or may not exist
+	RiskyCatchAll Status = "risky-catch-all"
+	// Unknown means the checks could not reach a verdict, for example
+	// because the server kept answering with transient 4xx replies or a
+	// DNS lookup failed
+	Unknown Status = "unknown"
+	// InvalidSyntax means the address is not well formed
+	InvalidSyntax Status = "invalid-syntax"
+	// NoMX means the domain exists but has no MX or address records
+	NoMX Status = "no-mx"
+	// NoDomain means the domain does not exist at all, often a typo
+	NoDomain Status = "no-domain"
+	// Timeout means verification ran out of time before reaching a verdict
+	Timeout Status = "timeout"
+	// Skipped means the SMTP check was not run, so nothing is known about
+	// the mailbox itself
+	Skipped Status = "skipped"
+)
+
+// Result holds the outcome of every check performed on an email address
+type Result struct {
+	Email            string
+	Normalized       bool
+	Canonical        string
+	SyntaxValid      bool
+	Domain           string
+	ASCIIDomain      string
+	DidYouMean       string
+	Suspicious       bool
+	SuspiciousReason string
+	Disposable       bool
+	RoleBased        bool
+	FreeProvider     bool
+	Gravatar         bool
+	DomainCreated    time.Time
+	DomainAgeDays    int
+	AllowListed      bool
+	DenyListed       bool
+	Reserved         bool
+	MXRecords        []string
+	MXPreferences    []uint16
+	ImplicitMX       bool
+	SPF              string
+	DMARC            string
+	MTASTS           bool
+	TLSRP            bool
+	MXHasPTR         bool
+	SMTPServer       string
+	SMTPPort         int
+	SMTPCode         int
+	SMTPMessage      string
+	SMTPDuration     time.Duration
+	TLSVersion       string
+	TLSValid         bool
+	TLSExpiry        time.Time
+	TLSFailed        bool
+	VRFYCode         int
+	VRFYMessage      string
+	CatchAll         bool
+	PlusAddressing   bool
+	Status           Status
+	Timeout          bool
+	Score            int
+	Reason           string
+}
+
+// Verify performs syntax, MX record, and SMTP checks on an email address
+// using DefaultOptions. Checks stop at the first failure and Reason explains
+// what went wrong. The returned error is non-nil when a network step could
+// not be completed, as opposed to the address being definitively invalid.
+func Verify(email string) (Result, error) {
+	return VerifyContext(context.Background(), email)
+}
+
+// VerifyContext is like Verify but gives up once ctx is done, marking the
+// result as timed out when its deadline passed
+func VerifyContext(ctx context.Context, email string) (Result, error) {
+	return std.Verify(ctx, email)
+}
+
+// Verify checks an email like the package-level Verify, using the
+// verifier's options, and gives up once ctx is done or Options.Timeout
+// passes
+func (v *Verifier) Verify(ctx context.Context, email string) (Result, error) {
+	if v.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, v.opts.Timeout)
+		defer cancel()
+	}
+	Logger.Debug("verifying", "email", email)
+	res, err := v.verify(ctx, email)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		res.Timeout = true
+		res.Reason = "verification timed out"
+		if stage := timedOutStage(err); stage != "" {
+			res.Reason += " during " + stage
+		}
+	}
+	switch {
+	case res.Timeout:
+		res.Status = Timeout
+	case res.Status == "":
+		res.Status = Unknown
+	}
+	// A failed custom rule is a definite answer, whatever the network said
+	if applyRules(email, &res) {
+		res.Timeout = false
+		err = nil
+	}
+	res.Score = score(res)
+	Logger.Info("verified", "email", email, "status", res.Status, "reason", res.Reason, "error", err)
+	return res, err
+}
+
+// verify runs each check in turn, stopping at the first failure
+func (v *Verifier) verify(ctx context.Context, email string) (Result, error) {
+	res := Result{Email: email}
+
+	// Check the address as it was meant, not as it was pasted
+	if cleaned := cleanInput(email); cleaned != email {
+		res.Normalized = true
+		email = cleaned
+	}
+
+	if err := validateEmail(email); err != nil {
+		res.Reason = "invalid email format: " + err.Error()
+		res.Status = InvalidSyntax
+		return res, nil
+	}
+
+	// Extract domain; a quoted local part may itself contain an @
+	at := strings.LastIndex(email, "@")
+	local := email[:at]
+	res.Domain = email[at+1:]
+
+	// DNS and SMTP need the punycode form of internationalized domains
+	asciiDomain, err := toASCIIDomain(res.Domain)
+	if err != nil {
+		res.Reason = "invalid domain name"
+		res.Status = InvalidSyntax
+		return res, nil
+	}
+	res.SyntaxValid = true
+	res.ASCIIDomain = asciiDomain
+	address := normalizeLocalPart(local) + "@" + asciiDomain
+	res.Canonical = canonicalize(address)
+
+	res.Disposable = isDisposableDomain(asciiDomain)
+	res.RoleBased = isRoleBased(email)
+	res.FreeProvider = isFreeProvider(asciiDomain)
+	res.Suspicious, res.SuspiciousReason = confusableCheck(email)
+	if suggestion, ok := suggestDomain(res.Domain); ok {
+		res.DidYouMean = local + "@" + suggestion
+	}
+
+	// Listed domains are settled without touching the network
+	key := strings.ToLower(asciiDomain)
+	if _, ok := v.deny[key]; ok {
+		res.DenyListed = true
+		res.Status = Undeliverable
+		res.Reason = "domain denied"
+		return res, nil
+	}
+	if _, ok := v.allow[key]; ok {
+		res.AllowListed = true
+		res.Status = Deliverable
+		res.Reason = "domain allowed"
+		return res, nil
+	}
+	if isReservedDomain(asciiDomain) {
+		res.Reserved = true
+		res.Status = Undeliverable
+		res.Reason = "reserved domain, not deliverable"
+		return res, nil
+	}
+
+	// Everything above is local; stop here when network checks are disabled
+	if v.opts.SyntaxOnly {
+		res.Status = Skipped
+		res.Reason = "syntax only, network checks skipped"
+		return res, nil
+	}
+
+	if v.opts.CheckGravatar {
+		res.Gravatar = hasGravatar(ctx, email)
+	}
+	if v.opts.CheckDomainAge {
+		res.DomainAgeDays = -1
+		if created := v.domainCreated(ctx, asciiDomain); !created.IsZero() {
+			res.DomainCreated = created
+			res.DomainAgeDays = int(time.Since(created).Hours() / 24)
+		}
+	}
+
+	// Check MX records and DNS policies, which are shared by every email
+	// at the domain
+	info, err := v.lookupDomain(ctx, asciiDomain)
+	if err != nil {
+		res.Timeout = isTimeout(err)
+		res.Reason = fmt.Sprintf("DNS lookup failed: %v", err)
+		if res.Timeout {
+			res.Reason = "DNS lookup timed out"
+		}
+		return res, err
+	}
+	if len(info.hosts) == 0 {
+		res.Status, res.Reason = info.noMail()
+		return res, nil
+	}
+	res.ImplicitMX = info.implicit
+	for _, mx := range info.hosts {
+		res.MXRecords = append(res.MXRecords, mx.Host)
+		res.MXPreferences = append(res.MXPreferences, mx.Pref)
+	}
+	res.SPF = info.spf
+	res.DMARC = info.dmarc
+	res.MTASTS = info.mtasts
+	res.TLSRP = info.tlsrpt
+	res.MXHasPTR = info.ptr
+
+	if v.opts.SkipSMTP {
+		res.Status = Skipped
+		res.Reason = "SMTP check skipped"
+		return res, nil
+	}
+
+	// Check if email exists via SMTP
+	if err := v.checkSMTP(ctx, address, info.hosts, &res); err != nil {
+		return res, err
+	}
+	return res, nil
+}